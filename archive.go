@@ -0,0 +1,382 @@
+package digdag
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// digdagIgnoreFile is the file listing patterns excluded from the project archive
+const digdagIgnoreFile = ".digdagignore"
+
+// ignorePattern is a pattern of .digdagignore
+type ignorePattern struct {
+	pattern  string
+	anchored bool // pattern contains `/`, so it matches the whole relative path
+	dirOnly  bool // pattern ends with `/`
+}
+
+// loadIgnorePatterns reads .digdagignore in dir. A missing file is not an error.
+func loadIgnorePatterns(dir string) ([]ignorePattern, error) {
+	f, err := os.Open(filepath.Join(dir, digdagIgnoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := ignorePattern{}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		p.pattern = line
+		patterns = append(patterns, p)
+	}
+	return patterns, scanner.Err()
+}
+
+// match reports whether the slash-separated relative path matches p
+func (p ignorePattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	name := path.Base(rel)
+	if p.anchored {
+		name = rel
+	}
+	ok, _ := path.Match(p.pattern, name)
+	return ok
+}
+
+func isIgnored(patterns []ignorePattern, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.match(rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// createProjectArchive creates the tar.gz archive of dir in the same way as `digdag push`.
+// Files and directories starting with `.` (including `.digdag/` state) and those
+// matching .digdagignore are excluded. Symbolic links to regular files are archived as files.
+func createProjectArchive(dir string) (*bytes.Buffer, error) {
+	patterns, err := loadIgnorePatterns(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if file == dir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if strings.HasPrefix(info.Name(), ".") || isIgnored(patterns, rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			info, err = os.Stat(file)
+			if err != nil {
+				return err
+			}
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return addArchiveFile(tw, file, rel, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func addArchiveFile(tw *tar.Writer, file, name string, info os.FileInfo) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ExtractProjectArchive extracts the tar.gz project archive read from r into dir.
+// Entries escaping dir by absolute paths, `..` or symbolic links are rejected.
+func ExtractProjectArchive(r io.Reader, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name, err := archiveEntryPath(hdr.Name)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+		target := filepath.Join(root, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := mkdirWithin(root, target); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := mkdirWithin(root, filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := writeArchiveFile(target, tr, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := mkdirWithin(root, filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := checkSymlink(root, target, hdr.Linkname); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			return &ArchiveEntryError{Name: hdr.Name, Reason: "unsupported file type"}
+		}
+	}
+}
+
+// ArchiveEntryError is returned when an entry of a project archive is unsafe to extract
+type ArchiveEntryError struct {
+	Name   string
+	Reason string
+}
+
+func (e *ArchiveEntryError) Error() string {
+	return "invalid archive entry `" + e.Name + "`: " + e.Reason
+}
+
+// archiveEntryPath returns the cleaned slash-separated path of an entry
+func archiveEntryPath(name string) (string, error) {
+	if strings.HasPrefix(name, "/") || strings.Contains(name, `\`) || filepath.IsAbs(name) {
+		return "", &ArchiveEntryError{Name: name, Reason: "absolute path"}
+	}
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", &ArchiveEntryError{Name: name, Reason: "path traversal"}
+	}
+	if cleaned == "." {
+		return "", nil
+	}
+	return cleaned, nil
+}
+
+// checkSymlink rejects symbolic links pointing outside of root.
+// `..` is only allowed at the beginning of link so that it climbs from the resolved directory of target.
+func checkSymlink(root, target, link string) error {
+	name := target
+	if rel, err := filepath.Rel(root, target); err == nil {
+		name = filepath.ToSlash(rel)
+	}
+
+	if path.IsAbs(link) || filepath.IsAbs(link) {
+		return &ArchiveEntryError{Name: name, Reason: "symbolic link to absolute path"}
+	}
+
+	seenName := false
+	for _, p := range strings.Split(link, "/") {
+		switch {
+		case p == "" || p == ".":
+		case p == "..":
+			if seenName {
+				return &ArchiveEntryError{Name: name, Reason: "symbolic link with `..` after a directory name"}
+			}
+		default:
+			seenName = true
+		}
+	}
+
+	parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	if !isWithin(root, filepath.Join(parent, filepath.FromSlash(link))) {
+		return &ArchiveEntryError{Name: name, Reason: "symbolic link escapes the directory"}
+	}
+	return nil
+}
+
+// mkdirWithin creates dir after checking that it does not resolve outside of root through symbolic links
+func mkdirWithin(root, dir string) error {
+	// Resolve the deepest existing ancestor, the rest does not contain symbolic links
+	existing := dir
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+	if !isWithin(root, resolved) {
+		name := dir
+		if rel, err := filepath.Rel(root, dir); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		return &ArchiveEntryError{Name: name, Reason: "directory escapes through symbolic link"}
+	}
+
+	return os.MkdirAll(dir, 0755)
+}
+
+func isWithin(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func writeArchiveFile(target string, r io.Reader, mode os.FileMode) error {
+	// Never write through an existing symbolic link
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return &ArchiveEntryError{Name: filepath.Base(target), Reason: "file overwrites symbolic link"}
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readProjectArchive reads the tar.gz project archive into a map of file name to content.
+// Symbolic links are read as their link target.
+func readProjectArchive(r io.Reader) (map[string][]byte, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := archiveEntryPath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeRegA:
+			content, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			files[name] = content
+		case tar.TypeSymlink:
+			files[name] = []byte(hdr.Linkname)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package digdag
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeFiles creates files under dir from a map of slash-separated path to content
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readArchive returns a map of file name to content in the tar.gz archive
+func readArchive(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gr.Close()
+
+	files := map[string]string{}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(content)
+	}
+	return files
+}
+
+func Test_createProjectArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"test.dig":                "+test:\n  echo>: test\n",
+		"queries/query.sql":       "SELECT 1",
+		"notes.txt":               "ignored by pattern",
+		"tmp/cache":               "ignored by directory pattern",
+		"sub/build/out":           "ignored by anchored pattern",
+		"build/out":               "not ignored",
+		".digdag/status":          "digdag state",
+		".hidden":                 "hidden file",
+		".digdagignore":           "# comment\n*.txt\ntmp/\nsub/build\n",
+		"queries/.git/HEAD":       "hidden directory",
+		"scripts/run.sh":          "#!/bin/sh",
+		"scripts/lib/helper.py":   "print(1)",
+		"scripts/lib/notes.txt":   "ignored by pattern in subdirectory",
+		"scripts/lib/.DS_Store":   "hidden file in subdirectory",
+		"scripts/lib/__init__.py": "",
+	})
+	if err := os.Symlink(filepath.Join(dir, "test.dig"), filepath.Join(dir, "link.dig")); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := createProjectArchive(dir)
+	if err != nil {
+		t.Fatalf("createProjectArchive() error = %v", err)
+	}
+	got := readArchive(t, archive)
+
+	want := map[string]string{
+		"test.dig":                "+test:\n  echo>: test\n",
+		"link.dig":                "+test:\n  echo>: test\n",
+		"queries/query.sql":       "SELECT 1",
+		"build/out":               "not ignored",
+		"scripts/run.sh":          "#!/bin/sh",
+		"scripts/lib/helper.py":   "print(1)",
+		"scripts/lib/__init__.py": "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		var names []string
+		for name := range got {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.Errorf("createProjectArchive() files = %v, want %v", names, want)
+	}
+}
+
+type testArchiveEntry struct {
+	name     string
+	content  string
+	linkname string
+	typeflag byte
+}
+
+// buildArchive creates a tar.gz archive from entries
+func buildArchive(t *testing.T, entries []testArchiveEntry) *bytes.Buffer {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		typeflag := e.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		hdr := &tar.Header{
+			Typeflag: typeflag,
+			Name:     e.name,
+			Linkname: e.linkname,
+			Mode:     0644,
+			Size:     int64(len(e.content)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestExtractProjectArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := buildArchive(t, []testArchiveEntry{
+		{name: "test.dig", content: "+test:\n  echo>: test\n"},
+		{name: "queries/", typeflag: tar.TypeDir},
+		{name: "queries/query.sql", content: "SELECT 1"},
+		{name: "scripts/lib/helper.py", content: "print(1)"},
+		{name: "queries/shared.dig", typeflag: tar.TypeSymlink, linkname: "../test.dig"},
+		{name: "current", typeflag: tar.TypeSymlink, linkname: "."},
+		{name: "current/via_link.txt", content: "via link"},
+	})
+	if err := ExtractProjectArchive(archive, dir); err != nil {
+		t.Fatalf("ExtractProjectArchive() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"test.dig":              "+test:\n  echo>: test\n",
+		"queries/query.sql":     "SELECT 1",
+		"scripts/lib/helper.py": "print(1)",
+		"queries/shared.dig":    "+test:\n  echo>: test\n",
+		"via_link.txt":          "via link",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("ReadFile(%v) error = %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("ReadFile(%v) = %v, want %v", name, string(got), want)
+		}
+	}
+}
+
+func TestExtractProjectArchive_Unsafe(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testArchiveEntry
+	}{
+		// Test cases
+		{
+			name:    "test path traversal",
+			entries: []testArchiveEntry{{name: "../evil.txt", content: "evil"}},
+		},
+		{
+			name:    "test nested path traversal",
+			entries: []testArchiveEntry{{name: "queries/../../evil.txt", content: "evil"}},
+		},
+		{
+			name:    "test absolute path",
+			entries: []testArchiveEntry{{name: "/tmp/evil.txt", content: "evil"}},
+		},
+		{
+			name:    "test symlink to absolute path",
+			entries: []testArchiveEntry{{name: "etc", typeflag: tar.TypeSymlink, linkname: "/etc"}},
+		},
+		{
+			name:    "test symlink escapes",
+			entries: []testArchiveEntry{{name: "queries/evil", typeflag: tar.TypeSymlink, linkname: "../../evil"}},
+		},
+		{
+			name: "test symlink escapes through another symlink",
+			entries: []testArchiveEntry{
+				{name: "current", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "current/evil", typeflag: tar.TypeSymlink, linkname: "../evil"},
+			},
+		},
+		{
+			name: "test symlink with `..` after a directory name",
+			entries: []testArchiveEntry{
+				{name: "evil", typeflag: tar.TypeSymlink, linkname: "current/.."},
+				{name: "current", typeflag: tar.TypeSymlink, linkname: "."},
+			},
+		},
+		{
+			name: "test file overwrites symlink",
+			entries: []testArchiveEntry{
+				{name: "link.dig", typeflag: tar.TypeSymlink, linkname: "test.dig"},
+				{name: "link.dig", content: "overwrite"},
+			},
+		},
+		{
+			name:    "test unsupported file type",
+			entries: []testArchiveEntry{{name: "fifo", typeflag: tar.TypeFifo}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			dir := filepath.Join(base, "project")
+			err := ExtractProjectArchive(buildArchive(t, tt.entries), dir)
+			var entryErr *ArchiveEntryError
+			if !errors.As(err, &entryErr) {
+				t.Errorf("ExtractProjectArchive() error = %v, want *ArchiveEntryError", err)
+			}
+			if _, err := os.Stat(filepath.Join(base, "evil.txt")); err == nil {
+				t.Error("file should not be written outside of the directory")
+			}
+		})
+	}
+}
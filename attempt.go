@@ -2,11 +2,13 @@ package digdag
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	uuid "github.com/satori/go.uuid"
 )
@@ -17,50 +19,58 @@ type attemptsWrapper struct {
 
 // Attempt is the struct for digdag attempt
 type Attempt struct {
-	ID      string `json:"id"`
-	Index   int    `json:"index"`
-	Project struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-	} `json:"project"`
+	ID       string     `json:"id"`
+	Index    int        `json:"index"`
+	Project  ProjectRef `json:"project"`
 	Workflow struct {
 		Name string `json:"name"`
 		ID   string `json:"id"`
 	} `json:"workflow"`
-	SessionID        string            `json:"sessionId"`
-	SessionUUID      string            `json:"sessionUuid"`
-	SessionTime      string            `json:"sessionTime"` // FIXME fix type from string to time.Time
-	RetryAttemptName interface{}       `json:"retryAttemptName,omitempty"`
-	Done             bool              `json:"done"`
-	Success          bool              `json:"success"`
-	CancelRequested  bool              `json:"cancelRequested"`
-	Params           map[string]string `json:"params"`
-	CreatedAt        string            `json:"createdAt"`
-	FinishedAt       string            `json:"finishedAt"`
+	SessionID        string      `json:"sessionId"`
+	SessionUUID      string      `json:"sessionUuid"`
+	SessionTime      time.Time   `json:"sessionTime"`
+	RetryAttemptName interface{} `json:"retryAttemptName,omitempty"`
+	Done             bool        `json:"done"`
+	Success          bool        `json:"success"`
+	CancelRequested  bool        `json:"cancelRequested"`
+	Params           Params      `json:"params"`
+	CreatedAt        time.Time   `json:"createdAt"`
+	FinishedAt       *time.Time  `json:"finishedAt"` // nil until the attempt is done
 }
 
 // CreateAttempt is struct for create a new attempt
 type CreateAttempt struct {
-	WorkflowID       string            `json:"workflowId"`
-	SessionTime      string            `json:"sessionTime"`
-	RetryAttemptName string            `json:"retryAttemptName,omitempty"`
-	Params           map[string]string `json:"params"`
+	WorkflowID       string    `json:"workflowId"`
+	SessionTime      time.Time `json:"sessionTime"`
+	RetryAttemptName string    `json:"retryAttemptName,omitempty"`
+	Params           Params    `json:"params"`
+	Resume           *Resume   `json:"resume,omitempty"`
+}
+
+// Resume is struct for resuming tasks of a previous attempt
+type Resume struct {
+	Mode      RetryMode `json:"mode"`
+	AttemptID string    `json:"attemptId"`
+	From      string    `json:"from,omitempty"`
 }
 
 // NewCreateAttempt to create a new CreateAttempt struct
-func NewCreateAttempt(workflowID, sessionTime, retryAttemptName string) *CreateAttempt {
+func NewCreateAttempt(workflowID string, sessionTime time.Time, retryAttemptName string) *CreateAttempt {
 	return &CreateAttempt{
 		WorkflowID:       workflowID,
 		SessionTime:      sessionTime,
 		RetryAttemptName: retryAttemptName,
-		Params:           map[string]string{},
+		Params:           Params{},
 	}
 }
 
 // GetAttempts get attempts response
 func (c *Client) GetAttempts(attempt *Attempt, includeRetried bool) ([]*Attempt, error) {
-	spath := "/api/attempts"
+	return c.GetAttemptsContext(context.Background(), attempt, includeRetried)
+}
 
+// GetAttemptsContext get attempts response with the given context
+func (c *Client) GetAttemptsContext(ctx context.Context, attempt *Attempt, includeRetried bool) ([]*Attempt, error) {
 	if attempt == nil {
 		attempt = new(Attempt)
 	}
@@ -68,6 +78,27 @@ func (c *Client) GetAttempts(attempt *Attempt, includeRetried bool) ([]*Attempt,
 	project := attempt.Project.Name
 	workflow := attempt.Workflow.Name
 
+	attempts, err := c.listAttempts(ctx, project, workflow, includeRetried, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// If any attempts not found
+	if len(attempts) == 0 {
+		return nil, &NotFoundError{
+			Name: fmt.Sprintf("project=%s workflow=%s", project, workflow),
+			Err:  ErrAttemptNotFound,
+		}
+	}
+
+	return attempts, nil
+}
+
+// listAttempts returns a page of attempts older than lastID, newest first.
+// An empty lastID or zero pageSize uses the default of digdag-server.
+func (c *Client) listAttempts(ctx context.Context, project, workflow string, includeRetried bool, lastID string, pageSize int) ([]*Attempt, error) {
+	spath := "/api/attempts"
+
 	var aw *attemptsWrapper
 	ro := &RequestOpts{
 		Params: map[string]string{
@@ -76,8 +107,14 @@ func (c *Client) GetAttempts(attempt *Attempt, includeRetried bool) ([]*Attempt,
 			"include_retried": strconv.FormatBool(includeRetried),
 		},
 	}
+	if lastID != "" {
+		ro.Params["last_id"] = lastID
+	}
+	if pageSize > 0 {
+		ro.Params["page_size"] = strconv.Itoa(pageSize)
+	}
 
-	resp, err := c.NewRequest(http.MethodGet, spath, ro)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, ro)
 	if err != nil {
 		return nil, err
 	}
@@ -86,21 +123,44 @@ func (c *Client) GetAttempts(attempt *Attempt, includeRetried bool) ([]*Attempt,
 		return nil, err
 	}
 
-	// If any attempts not found
-	if len(aw.Attempts) == 0 {
-		return nil, fmt.Errorf("attempts does not exist. project=%s workflow=%s", project, workflow)
+	return aw.Attempts, nil
+}
+
+// GetAttempt to get attempt by attempt ID
+func (c *Client) GetAttempt(attemptID string) (*Attempt, error) {
+	return c.GetAttemptContext(context.Background(), attemptID)
+}
+
+// GetAttemptContext to get attempt by attempt ID with the given context
+func (c *Client) GetAttemptContext(ctx context.Context, attemptID string) (*Attempt, error) {
+	spath := fmt.Sprintf("/api/attempts/%s", attemptID)
+
+	var attempt *Attempt
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, notFound(err, "id="+attemptID, ErrAttemptNotFound)
 	}
 
-	return aw.Attempts, nil
+	if err := decodeBody(resp, &attempt); err != nil {
+		return nil, err
+	}
+
+	return attempt, nil
 }
 
-// GetAttemptIDs to get attemptID from sessionTime
-func (c *Client) GetAttemptIDs(projectName, workflowName, targetSession string) (attemptIDs []string, err error) {
+// GetAttemptIDs to get attemptID from sessionTime.
+// Session times are compared as instants regardless of their offsets.
+func (c *Client) GetAttemptIDs(projectName, workflowName string, targetSession time.Time) (attemptIDs []string, err error) {
+	return c.GetAttemptIDsContext(context.Background(), projectName, workflowName, targetSession)
+}
+
+// GetAttemptIDsContext to get attemptID from sessionTime with the given context
+func (c *Client) GetAttemptIDsContext(ctx context.Context, projectName, workflowName string, targetSession time.Time) (attemptIDs []string, err error) {
 	params := new(Attempt)
 	params.Project.Name = projectName
 	params.Workflow.Name = workflowName
 
-	attempts, err := c.GetAttempts(params, true)
+	attempts, err := c.GetAttemptsContext(ctx, params, true)
 	if err != nil {
 		return nil, err
 	}
@@ -108,33 +168,37 @@ func (c *Client) GetAttemptIDs(projectName, workflowName, targetSession string)
 	for k := range attempts {
 		sessionTime := attempts[k].SessionTime
 
-		if sessionTime == targetSession {
+		if sessionTime.Equal(targetSession) {
 			attemptIDs = append(attemptIDs, attempts[k].ID)
 		}
 	}
 
 	// If any attemptID not found
 	if len(attemptIDs) == 0 {
-		return []string{}, fmt.Errorf("attempts does not exist. project=%s workflow=%s sessionTime=%s", projectName, workflowName, targetSession)
+		return []string{}, &NotFoundError{
+			Name: fmt.Sprintf("project=%s workflow=%s sessionTime=%s", projectName, workflowName, targetSession.Format(time.RFC3339)),
+			Err:  ErrAttemptNotFound,
+		}
 	}
 
 	return attemptIDs, nil
 }
 
-// CreateNewAttempt to create a new attempt
-func (c *Client) CreateNewAttempt(workflowID, sessionTime string, params []string, retry bool) (attempt *Attempt, done bool, err error) {
-	spath := "/api/attempts"
+// CreateNewAttempt to create a new attempt.
+// params are given as `key=value` or `key:=json`, see ParseParams.
+// done is true if the session already exists. Use StartWorkflow to get the existing attempt.
+func (c *Client) CreateNewAttempt(workflowID string, sessionTime time.Time, params []string, retry bool) (attempt *Attempt, done bool, err error) {
+	return c.CreateNewAttemptContext(context.Background(), workflowID, sessionTime, params, retry)
+}
 
+// CreateNewAttemptContext to create a new attempt with the given context
+func (c *Client) CreateNewAttemptContext(ctx context.Context, workflowID string, sessionTime time.Time, params []string, retry bool) (attempt *Attempt, done bool, err error) {
 	ca := NewCreateAttempt(workflowID, sessionTime, "")
 
 	// Set params
-	for _, v := range params {
-		if strings.Contains(v, "=") {
-			result := strings.Split(v, "=")
-			key := result[0]
-			val := result[1]
-			ca.Params[key] = val
-		}
+	ca.Params, err = ParseParams(params)
+	if err != nil {
+		return nil, false, err
 	}
 
 	// Retry workflow
@@ -149,28 +213,288 @@ func (c *Client) CreateNewAttempt(workflowID, sessionTime string, params []strin
 	}
 
 	// Create new attempt
-	body, err := json.Marshal(ca)
+	resp, err := c.putAttempt(ctx, ca)
 	if err != nil {
+		// if already session exist
+		if IsConflict(err) {
+			return nil, true, nil
+		}
+
 		return nil, false, err
 	}
 
+	if err := decodeBody(resp, &attempt); err != nil {
+		return nil, false, err
+	}
+
+	return attempt, done, err
+}
+
+// putAttempt sends `PUT /api/attempts` to create the attempt.
+// It is replayed on retry only if RetryPolicy.RetryCreateAttempt is set.
+func (c *Client) putAttempt(ctx context.Context, ca *CreateAttempt) (*http.Response, error) {
+	body, err := json.Marshal(ca)
+	if err != nil {
+		return nil, err
+	}
+
 	ro := &RequestOpts{
-		Body: bytes.NewBuffer(body),
+		Body:       bytes.NewBuffer(body),
+		Idempotent: c.RetryPolicy != nil && c.RetryPolicy.RetryCreateAttempt,
 	}
+	return c.NewRequestContext(ctx, http.MethodPut, "/api/attempts", ro)
+}
+
+// KillAttempt to request to kill the attempt
+func (c *Client) KillAttempt(attemptID string) error {
+	return c.KillAttemptContext(context.Background(), attemptID)
+}
+
+// KillAttemptContext to request to kill the attempt with the given context
+func (c *Client) KillAttemptContext(ctx context.Context, attemptID string) error {
+	spath := fmt.Sprintf("/api/attempts/%s/kill", attemptID)
 
-	resp, err := c.NewRequest(http.MethodPut, spath, ro)
+	resp, err := c.NewRequestContext(ctx, http.MethodPost, spath, &RequestOpts{Idempotent: true})
 	if err != nil {
-		// if already session exist
-		if resp.StatusCode == http.StatusConflict {
-			return nil, true, nil
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// KillAttemptsFilter is the filter to select attempts to kill
+type KillAttemptsFilter struct {
+	Project  string
+	Workflow string
+	// SessionTimeFrom and SessionTimeTo limit the session time to [from, to). A zero value means no limit.
+	SessionTimeFrom time.Time
+	SessionTimeTo   time.Time
+	// RunningOnly skips attempts that are done or already requested to be killed
+	RunningOnly bool
+	// DryRun reports the matching attempts without killing them
+	DryRun bool
+}
+
+func (f *KillAttemptsFilter) match(attempt *Attempt) bool {
+	if f.RunningOnly && (attempt.Done || attempt.CancelRequested) {
+		return false
+	}
+	if !f.SessionTimeFrom.IsZero() && attempt.SessionTime.Before(f.SessionTimeFrom) {
+		return false
+	}
+	if !f.SessionTimeTo.IsZero() && !attempt.SessionTime.Before(f.SessionTimeTo) {
+		return false
+	}
+	return true
+}
+
+// killAttemptsPageSize is the number of attempts fetched at once by KillAttempts
+const killAttemptsPageSize = 100
+
+// KillAttempts to kill the attempts matching filter and return them.
+// All attempts of the project and workflow are fetched page by page.
+// Attempts that finished before being killed are not returned.
+func (c *Client) KillAttempts(filter KillAttemptsFilter) ([]*Attempt, error) {
+	return c.KillAttemptsContext(context.Background(), filter)
+}
+
+// KillAttemptsContext to kill the attempts matching filter with the given context
+func (c *Client) KillAttemptsContext(ctx context.Context, filter KillAttemptsFilter) ([]*Attempt, error) {
+	var killed []*Attempt
+	lastID := ""
+	for {
+		attempts, err := c.listAttempts(ctx, filter.Project, filter.Workflow, true, lastID, killAttemptsPageSize)
+		if err != nil {
+			return killed, err
+		}
+
+		for _, attempt := range attempts {
+			if !filter.match(attempt) {
+				continue
+			}
+			if !filter.DryRun {
+				if err := c.KillAttemptContext(ctx, attempt.ID); err != nil {
+					// the attempt is already done
+					if IsConflict(err) {
+						continue
+					}
+					return killed, err
+				}
+			}
+			killed = append(killed, attempt)
+		}
+
+		// Session times are not ordered by ID, e.g. a backfill creates new attempts of old sessions,
+		// so all pages are walked
+		if len(attempts) < killAttemptsPageSize {
+			return killed, nil
+		}
+		lastID = attempts[len(attempts)-1].ID
+	}
+}
+
+// RetryMode is the mode of RetryAttempt
+type RetryMode string
+
+// Retry modes
+const (
+	// RetryAll reruns all tasks
+	RetryAll RetryMode = "all"
+	// ResumeFailed reruns only the failed tasks
+	ResumeFailed RetryMode = "failed"
+	// ResumeFrom reruns the tasks from RetryOptions.From
+	ResumeFrom RetryMode = "from"
+)
+
+// RetryOptions is the options for RetryAttempt
+type RetryOptions struct {
+	// Mode defaults to RetryAll
+	Mode RetryMode
+	// From is the full name of the task to resume from with ResumeFrom
+	From string
+	// Name is the retry attempt name. If empty, a random UUID is used.
+	Name string
+	// Params overrides the params of the attempt
+	Params map[string]interface{}
+	// LatestRevision retries with the latest revision of the workflow like `digdag retry --latest-revision`.
+	// By default, the revision of the attempt is kept.
+	LatestRevision bool
+}
+
+// RetryAttempt to create a new attempt retrying the attempt.
+// The attempt params are kept and overridden by opts.Params.
+func (c *Client) RetryAttempt(attemptID string, opts RetryOptions) (*Attempt, error) {
+	return c.RetryAttemptContext(context.Background(), attemptID, opts)
+}
+
+// RetryAttemptContext to create a new attempt retrying the attempt with the given context
+func (c *Client) RetryAttemptContext(ctx context.Context, attemptID string, opts RetryOptions) (*Attempt, error) {
+	var resume *Resume
+	switch opts.Mode {
+	case "", RetryAll:
+	case ResumeFailed:
+		resume = &Resume{Mode: ResumeFailed, AttemptID: attemptID}
+	case ResumeFrom:
+		if opts.From == "" {
+			return nil, fmt.Errorf("task name to resume from is required")
+		}
+		resume = &Resume{Mode: ResumeFrom, AttemptID: attemptID, From: opts.From}
+	default:
+		return nil, fmt.Errorf("retry mode `%s` is invalid", opts.Mode)
+	}
+
+	attempt, err := c.GetAttemptContext(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = uuid.NewV4().String()
+	}
+
+	workflowID := attempt.Workflow.ID
+	if opts.LatestRevision {
+		workflow, err := c.GetWorkflowContext(ctx, attempt.Project.ID, attempt.Workflow.Name)
+		if err != nil {
+			return nil, err
 		}
+		workflowID = workflow.ID
+	}
+
+	ca := NewCreateAttempt(workflowID, attempt.SessionTime, name)
+	ca.Resume = resume
+	for k, v := range attempt.Params {
+		ca.Params[k] = v
+	}
+	for k, v := range opts.Params {
+		ca.Params[k] = v
+	}
+
+	resp, err := c.putAttempt(ctx, ca)
+	if err != nil {
+		return nil, err
+	}
+
+	var retried *Attempt
+	if err := decodeBody(resp, &retried); err != nil {
+		return nil, err
+	}
+
+	return retried, nil
+}
+
+// StartWorkflow to start the workflow of the project for the session time.
+// If the session already exists, the existing attempt is returned with existing = true.
+func (c *Client) StartWorkflow(projectName, workflowName string, sessionTime time.Time, params map[string]interface{}) (attempt *Attempt, existing bool, err error) {
+	return c.StartWorkflowContext(context.Background(), projectName, workflowName, sessionTime, params)
+}
 
+// StartWorkflowContext to start the workflow of the project for the session time with the given context
+func (c *Client) StartWorkflowContext(ctx context.Context, projectName, workflowName string, sessionTime time.Time, params map[string]interface{}) (attempt *Attempt, existing bool, err error) {
+	project, err := c.GetProjectContext(ctx, projectName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	workflow, err := c.GetWorkflowContext(ctx, project.ID, workflowName)
+	if err != nil {
 		return nil, false, err
 	}
 
+	ca := NewCreateAttempt(workflow.ID, sessionTime, "")
+	for k, v := range params {
+		ca.Params[k] = v
+	}
+
+	resp, err := c.putAttempt(ctx, ca)
+	if err != nil {
+		if !IsConflict(err) {
+			return nil, false, err
+		}
+		attempt, err = c.conflictedAttempt(ctx, err, projectName, workflowName, sessionTime)
+		if err != nil {
+			return nil, false, err
+		}
+		return attempt, true, nil
+	}
+
 	if err := decodeBody(resp, &attempt); err != nil {
 		return nil, false, err
 	}
 
-	return attempt, done, err
+	return attempt, false, nil
+}
+
+// conflictedAttempt returns the existing attempt of the session.
+// digdag-server returns it as the body of the conflict response, otherwise the attempts are looked up.
+func (c *Client) conflictedAttempt(ctx context.Context, err error, projectName, workflowName string, sessionTime time.Time) (*Attempt, error) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		var attempt *Attempt
+		if json.Unmarshal(apiErr.Body, &attempt) == nil && attempt != nil && attempt.ID != "" {
+			return attempt, nil
+		}
+	}
+
+	params := new(Attempt)
+	params.Project.Name = projectName
+	params.Workflow.Name = workflowName
+
+	attempts, err := c.GetAttemptsContext(ctx, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attempt := range attempts {
+		if attempt.SessionTime.Equal(sessionTime) {
+			return attempt, nil
+		}
+	}
+
+	return nil, &NotFoundError{
+		Name: fmt.Sprintf("project=%s workflow=%s sessionTime=%s", projectName, workflowName, sessionTime.Format(time.RFC3339)),
+		Err:  ErrAttemptNotFound,
+	}
 }
@@ -1,14 +1,23 @@
 package digdag
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestClient_GetAttempts(t *testing.T) {
+	sessionTime, _ := time.Parse("2006-01-02T15:04:05-07:00", "2017-06-24T00:00:00+00:00")
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-24T06:45:26Z")
+	finishedAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-24T06:45:31Z")
 	type args struct {
 		attempt        *Attempt
 		includeRetried bool
@@ -24,10 +33,7 @@ func TestClient_GetAttempts(t *testing.T) {
 		{
 			args: args{
 				attempt: &Attempt{
-					Project: struct {
-						ID   string `json:"id"`
-						Name string `json:"name"`
-					}{
+					Project: ProjectRef{
 						ID: "1",
 					},
 					Workflow: struct {
@@ -71,10 +77,7 @@ func TestClient_GetAttempts(t *testing.T) {
 				{
 					ID:    "27",
 					Index: 1,
-					Project: struct {
-						ID   string `json:"id"`
-						Name string `json:"name"`
-					}{
+					Project: ProjectRef{
 						ID:   "1",
 						Name: "test",
 					},
@@ -87,14 +90,14 @@ func TestClient_GetAttempts(t *testing.T) {
 					},
 					SessionID:        "9",
 					SessionUUID:      "15624750-5c1f-45d2-b668-c4f86e757484",
-					SessionTime:      "2017-06-24T00:00:00+00:00",
+					SessionTime:      sessionTime,
 					RetryAttemptName: nil,
 					Done:             true,
 					Success:          false,
 					CancelRequested:  false,
-					Params:           map[string]string{},
-					CreatedAt:        "2017-06-24T06:45:26Z",
-					FinishedAt:       "2017-06-24T06:45:31Z",
+					Params:           map[string]interface{}{},
+					CreatedAt:        createdAt,
+					FinishedAt:       &finishedAt,
 				},
 			},
 		},
@@ -135,10 +138,7 @@ func TestClient_GetAttempts(t *testing.T) {
 				{
 					ID:    "27",
 					Index: 1,
-					Project: struct {
-						ID   string `json:"id"`
-						Name string `json:"name"`
-					}{
+					Project: ProjectRef{
 						ID:   "1",
 						Name: "test",
 					},
@@ -151,24 +151,21 @@ func TestClient_GetAttempts(t *testing.T) {
 					},
 					SessionID:        "9",
 					SessionUUID:      "15624750-5c1f-45d2-b668-c4f86e757484",
-					SessionTime:      "2017-06-24T00:00:00+00:00",
+					SessionTime:      sessionTime,
 					RetryAttemptName: nil,
 					Done:             true,
 					Success:          false,
 					CancelRequested:  false,
-					Params:           map[string]string{},
-					CreatedAt:        "2017-06-24T06:45:26Z",
-					FinishedAt:       "2017-06-24T06:45:31Z",
+					Params:           map[string]interface{}{},
+					CreatedAt:        createdAt,
+					FinishedAt:       &finishedAt,
 				},
 			},
 		},
 		{
 			args: args{
 				attempt: &Attempt{
-					Project: struct {
-						ID   string `json:"id"`
-						Name string `json:"name"`
-					}{
+					Project: ProjectRef{
 						ID: "11111",
 					},
 					Workflow: struct {
@@ -211,7 +208,7 @@ func TestClient_GetAttemptIDs(t *testing.T) {
 	type args struct {
 		projectName   string
 		workflowName  string
-		targetSession string
+		targetSession time.Time
 	}
 	tests := []struct {
 		name           string
@@ -225,7 +222,7 @@ func TestClient_GetAttemptIDs(t *testing.T) {
 			args: args{
 				projectName:   "test",
 				workflowName:  "test",
-				targetSession: "2017-06-24T00:00:00+00:00",
+				targetSession: time.Date(2017, 6, 24, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
 			},
 			res: `
 			{
@@ -261,7 +258,7 @@ func TestClient_GetAttemptIDs(t *testing.T) {
 			args: args{
 				projectName:   "test",
 				workflowName:  "test",
-				targetSession: "2017-06-25T00:00:00+00:00",
+				targetSession: time.Date(2017, 6, 25, 0, 0, 0, 0, time.UTC),
 			},
 			res: `
 			{
@@ -319,9 +316,10 @@ func TestClient_GetAttemptIDs(t *testing.T) {
 }
 
 func TestNewCreateAttempt(t *testing.T) {
+	sessionTime, _ := time.Parse("2006-01-02T15:04:05-07:00", "2017-06-24T00:00:00+00:00")
 	type args struct {
 		workflowID       string
-		sessionTime      string
+		sessionTime      time.Time
 		retryAttemptName string
 	}
 	tests := []struct {
@@ -333,14 +331,14 @@ func TestNewCreateAttempt(t *testing.T) {
 		{
 			args: args{
 				workflowID:       "2",
-				sessionTime:      "2017-06-24T00:00:00+00:00",
+				sessionTime:      sessionTime,
 				retryAttemptName: "",
 			},
 			want: &CreateAttempt{
 				WorkflowID:       "2",
-				SessionTime:      "2017-06-24T00:00:00+00:00",
+				SessionTime:      sessionTime,
 				RetryAttemptName: "",
-				Params:           map[string]string{},
+				Params:           map[string]interface{}{},
 			},
 		},
 	}
@@ -353,10 +351,55 @@ func TestNewCreateAttempt(t *testing.T) {
 	}
 }
 
+func TestAttempt_JSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		res  string
+	}{
+		{
+			name: "test finished attempt",
+			res:  `{"id":"27","sessionTime":"2017-06-24T09:00:00+09:00","createdAt":"2017-06-24T06:45:26Z","finishedAt":"2017-06-24T06:45:31Z"}`,
+		},
+		{
+			name: "test running attempt",
+			res:  `{"id":"27","sessionTime":"2017-06-24T00:00:00+00:00","createdAt":"2017-06-24T06:45:26Z","finishedAt":null}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want Attempt
+			if err := json.Unmarshal([]byte(tt.res), &want); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			b, err := json.Marshal(want)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			var got Attempt
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if !got.SessionTime.Equal(want.SessionTime) || !got.CreatedAt.Equal(want.CreatedAt) {
+				t.Errorf("round trip = %v, want %v", got, want)
+			}
+			if (got.FinishedAt == nil) != (want.FinishedAt == nil) || (got.FinishedAt != nil && !got.FinishedAt.Equal(*want.FinishedAt)) {
+				t.Errorf("round trip FinishedAt = %v, want %v", got.FinishedAt, want.FinishedAt)
+			}
+			_, gotOffset := got.SessionTime.Zone()
+			_, wantOffset := want.SessionTime.Zone()
+			if gotOffset != wantOffset {
+				t.Errorf("round trip SessionTime offset = %v, want %v", got.SessionTime, want.SessionTime)
+			}
+		})
+	}
+}
+
 func TestClient_CreateNewAttempt(t *testing.T) {
+	sessionTime, _ := time.Parse("2006-01-02T15:04:05-07:00", "2017-06-24T00:00:00+00:00")
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-24T06:45:26Z")
 	type args struct {
 		workflowID  string
-		sessionTime string
+		sessionTime time.Time
 		params      []string
 		retry       bool
 	}
@@ -373,7 +416,7 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 			name: "test start a attempt",
 			args: args{
 				workflowID:  "2",
-				sessionTime: "2017-06-24T00:00:00+00:00",
+				sessionTime: sessionTime,
 				params:      []string{"key=value"},
 				retry:       false,
 			},
@@ -395,7 +438,7 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 				"success": false,
 				"cancelRequested": false,
 				"createdAt": "2017-06-24T06:45:26Z",
-				"finishedAt": "",
+				"finishedAt": null,
 				"workflowId": "2",
 				"sessionTime": "2017-06-24T00:00:00+00:00",
 				"params": {
@@ -406,11 +449,9 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 			wantAttempt: &Attempt{
 				ID:    "27",
 				Index: 1,
-				Project: struct {
-					ID   string `json:"id"`
-					Name string `json:"name"`
-				}{
-					"1", "test",
+				Project: ProjectRef{
+					ID:   "1",
+					Name: "test",
 				},
 				Workflow: struct {
 					Name string `json:"name"`
@@ -423,16 +464,16 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 				Done:            false,
 				Success:         false,
 				CancelRequested: false,
-				CreatedAt:       "2017-06-24T06:45:26Z",
-				Params:          map[string]string{"key": "value"},
-				SessionTime:     "2017-06-24T00:00:00+00:00",
+				CreatedAt:       createdAt,
+				Params:          map[string]interface{}{"key": "value"},
+				SessionTime:     sessionTime,
 			},
 		},
 		{
 			name: "test if already a session has done",
 			args: args{
 				workflowID:  "2",
-				sessionTime: "2017-06-24T00:00:00+00:00",
+				sessionTime: sessionTime,
 				params:      []string{},
 				retry:       true,
 			},
@@ -442,7 +483,7 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 			name: "test retry a attempt",
 			args: args{
 				workflowID:  "2",
-				sessionTime: "2017-06-24T00:00:00+00:00",
+				sessionTime: sessionTime,
 				params:      []string{},
 				retry:       true,
 			},
@@ -464,7 +505,7 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 				"success": false,
 				"cancelRequested": false,
 				"createdAt": "2017-06-24T06:45:26Z",
-				"finishedAt": "",
+				"finishedAt": null,
 				"workflowId": "2",
 				"sessionTime": "2017-06-24T00:00:00+00:00",
 				"params": {}
@@ -473,11 +514,9 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 			wantAttempt: &Attempt{
 				ID:    "27",
 				Index: 1,
-				Project: struct {
-					ID   string `json:"id"`
-					Name string `json:"name"`
-				}{
-					"1", "test",
+				Project: ProjectRef{
+					ID:   "1",
+					Name: "test",
 				},
 				Workflow: struct {
 					Name string `json:"name"`
@@ -490,9 +529,9 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 				Done:            false,
 				Success:         false,
 				CancelRequested: false,
-				CreatedAt:       "2017-06-24T06:45:26Z",
-				Params:          map[string]string{},
-				SessionTime:     "2017-06-24T00:00:00+00:00",
+				CreatedAt:       createdAt,
+				Params:          map[string]interface{}{},
+				SessionTime:     sessionTime,
 			},
 		},
 	}
@@ -525,3 +564,678 @@ func TestClient_CreateNewAttempt(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetAttempt(t *testing.T) {
+	sessionTime, _ := time.Parse("2006-01-02T15:04:05-07:00", "2017-06-24T00:00:00+00:00")
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-24T06:45:26Z")
+
+	attempt := &Attempt{
+		ID:          "27",
+		Index:       1,
+		SessionID:   "9",
+		SessionUUID: "15624750-5c1f-45d2-b668-c4f86e757484",
+		SessionTime: sessionTime,
+		Params:      map[string]interface{}{},
+		CreatedAt:   createdAt,
+	}
+	attempt.Project.ID = "1"
+	attempt.Project.Name = "test"
+	attempt.Workflow.ID = "2"
+	attempt.Workflow.Name = "test"
+
+	type args struct {
+		attemptID string
+	}
+	tests := []struct {
+		name         string
+		args         args
+		resFile      string
+		res          string
+		status       int
+		want         *Attempt
+		wantErr      bool
+		wantNotFound bool
+	}{
+		// Test cases
+		{
+			args:    args{attemptID: "27"},
+			resFile: "testdata/new_attempt.json",
+			status:  http.StatusOK,
+			want:    attempt,
+		},
+		{
+			name:         "test attempt not found",
+			args:         args{attemptID: "999"},
+			res:          `{"message":"Resource does not exist: attempt id=999","status":404}`,
+			status:       http.StatusNotFound,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/attempts/%s", tt.args.attemptID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				if tt.resFile != "" {
+					http.ServeFile(w, r, tt.resFile)
+					return
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.GetAttempt(tt.args.attemptID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.GetAttempt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", IsNotFound(err), tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetAttempt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_KillAttempt(t *testing.T) {
+	tests := []struct {
+		name         string
+		attemptID    string
+		status       int
+		wantErr      bool
+		wantConflict bool
+	}{
+		// Test cases
+		{
+			attemptID: "27",
+			status:    http.StatusNoContent,
+		},
+		{
+			name:         "test attempt already done",
+			attemptID:    "28",
+			status:       http.StatusConflict,
+			wantErr:      true,
+			wantConflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/attempts/%s/kill", tt.attemptID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				if r.Method != http.MethodPost {
+					t.Errorf("Method = %v, want : %v", r.Method, http.MethodPost)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			err := c.KillAttempt(tt.attemptID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.KillAttempt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsConflict(err) != tt.wantConflict {
+				t.Errorf("IsConflict() = %v, want %v", IsConflict(err), tt.wantConflict)
+			}
+		})
+	}
+}
+
+func TestClient_KillAttempts(t *testing.T) {
+	res := `
+	{
+		"attempts": [
+			{"id": "1", "sessionTime": "2017-06-24T00:00:00+00:00", "done": false, "cancelRequested": false},
+			{"id": "2", "sessionTime": "2017-06-25T00:00:00+00:00", "done": false, "cancelRequested": true},
+			{"id": "3", "sessionTime": "2017-06-26T00:00:00+00:00", "done": true, "cancelRequested": false},
+			{"id": "4", "sessionTime": "2017-06-27T09:00:00+09:00", "done": false, "cancelRequested": false}
+		]
+	}
+	`
+	tests := []struct {
+		name       string
+		res        string
+		filter     KillAttemptsFilter
+		wantIDs    []string
+		wantKilled []string
+	}{
+		// Test cases
+		{
+			name:       "test all attempts",
+			res:        res,
+			filter:     KillAttemptsFilter{Project: "test", Workflow: "test"},
+			wantIDs:    []string{"1", "2", "4"},
+			wantKilled: []string{"1", "2", "3", "4"},
+		},
+		{
+			name:       "test running only",
+			res:        res,
+			filter:     KillAttemptsFilter{Project: "test", RunningOnly: true},
+			wantIDs:    []string{"1", "4"},
+			wantKilled: []string{"1", "4"},
+		},
+		{
+			name: "test session time range",
+			res:  res,
+			filter: KillAttemptsFilter{
+				SessionTimeFrom: time.Date(2017, 6, 25, 0, 0, 0, 0, time.UTC),
+				SessionTimeTo:   time.Date(2017, 6, 27, 0, 0, 0, 0, time.UTC),
+			},
+			wantIDs:    []string{"2"},
+			wantKilled: []string{"2", "3"},
+		},
+		{
+			name: "test session time range compares instants",
+			res:  res,
+			filter: KillAttemptsFilter{
+				SessionTimeFrom: time.Date(2017, 6, 27, 0, 0, 0, 0, time.UTC),
+			},
+			wantIDs:    []string{"4"},
+			wantKilled: []string{"4"},
+		},
+		{
+			name:    "test dry run",
+			res:     res,
+			filter:  KillAttemptsFilter{RunningOnly: true, DryRun: true},
+			wantIDs: []string{"1", "4"},
+		},
+		{
+			name:   "test attempts not found",
+			res:    `{"attempts":[]}`,
+			filter: KillAttemptsFilter{Project: "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var killed []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/attempts" {
+					if got := r.URL.Query().Get("project"); got != tt.filter.Project {
+						t.Errorf("project = %v, want : %v", got, tt.filter.Project)
+					}
+					if got := r.URL.Query().Get("workflow"); got != tt.filter.Workflow {
+						t.Errorf("workflow = %v, want : %v", got, tt.filter.Workflow)
+					}
+					fmt.Fprintln(w, tt.res)
+					return
+				}
+				id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/attempts/"), "/kill")
+				mu.Lock()
+				killed = append(killed, id)
+				mu.Unlock()
+				if id == "3" {
+					w.WriteHeader(http.StatusConflict)
+					fmt.Fprintln(w, `{"message":"Attempt is already done","status":409}`)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.KillAttempts(tt.filter)
+			if err != nil {
+				t.Errorf("Client.KillAttempts() error = %v", err)
+				return
+			}
+			var gotIDs []string
+			for _, a := range got {
+				gotIDs = append(gotIDs, a.ID)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("Client.KillAttempts() = %v, want %v", gotIDs, tt.wantIDs)
+			}
+			if !reflect.DeepEqual(killed, tt.wantKilled) {
+				t.Errorf("killed = %v, want %v", killed, tt.wantKilled)
+			}
+		})
+	}
+}
+
+func TestClient_RetryAttempt(t *testing.T) {
+	sessionTime := time.Date(2017, 6, 24, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		opts         RetryOptions
+		status       int
+		want         *CreateAttempt
+		wantErr      bool
+		wantConflict bool
+	}{
+		// Test cases
+		{
+			name:   "test rerun all",
+			opts:   RetryOptions{Name: "retry1"},
+			status: http.StatusOK,
+			want: &CreateAttempt{
+				WorkflowID:       "2",
+				SessionTime:      sessionTime,
+				RetryAttemptName: "retry1",
+				Params:           map[string]interface{}{"a": "1", "b": "2"},
+			},
+		},
+		{
+			name:   "test resume failed",
+			opts:   RetryOptions{Mode: ResumeFailed, Name: "retry2", Params: map[string]interface{}{"b": "3", "c": "4"}},
+			status: http.StatusOK,
+			want: &CreateAttempt{
+				WorkflowID:       "2",
+				SessionTime:      sessionTime,
+				RetryAttemptName: "retry2",
+				Params:           map[string]interface{}{"a": "1", "b": "3", "c": "4"},
+				Resume:           &Resume{Mode: ResumeFailed, AttemptID: "27"},
+			},
+		},
+		{
+			name:   "test resume from",
+			opts:   RetryOptions{Mode: ResumeFrom, From: "+test+b", Name: "retry3"},
+			status: http.StatusOK,
+			want: &CreateAttempt{
+				WorkflowID:       "2",
+				SessionTime:      sessionTime,
+				RetryAttemptName: "retry3",
+				Params:           map[string]interface{}{"a": "1", "b": "2"},
+				Resume:           &Resume{Mode: ResumeFrom, AttemptID: "27", From: "+test+b"},
+			},
+		},
+		{
+			name:   "test latest revision",
+			opts:   RetryOptions{Name: "retry4", LatestRevision: true},
+			status: http.StatusOK,
+			want: &CreateAttempt{
+				WorkflowID:       "5",
+				SessionTime:      sessionTime,
+				RetryAttemptName: "retry4",
+				Params:           map[string]interface{}{"a": "1", "b": "2"},
+			},
+		},
+		{
+			name:    "test resume from without task",
+			opts:    RetryOptions{Mode: ResumeFrom},
+			wantErr: true,
+		},
+		{
+			name:    "test invalid mode",
+			opts:    RetryOptions{Mode: "hoge"},
+			wantErr: true,
+		},
+		{
+			name:         "test retry name already used",
+			opts:         RetryOptions{Name: "retry1"},
+			status:       http.StatusConflict,
+			wantErr:      true,
+			wantConflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *CreateAttempt
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/attempts/27":
+					fmt.Fprintln(w, `{"id": "27", "project": {"id": "3", "name": "test"}, "workflow": {"name": "test", "id": "2"}, "sessionTime": "2017-06-24T00:00:00+00:00", "params": {"a": "1", "b": "2"}}`)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/projects/3/workflows":
+					if got := r.URL.Query().Get("name"); got != "test" {
+						t.Errorf("name = %v, want : %v", got, "test")
+					}
+					fmt.Fprintln(w, `{"workflows": [{"id": "5", "name": "test", "project": {"id": "3", "name": "test"}}]}`)
+				case r.Method == http.MethodPut && r.URL.Path == "/api/attempts":
+					if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+						t.Errorf("decode request body: %v", err)
+					}
+					w.WriteHeader(tt.status)
+					fmt.Fprintln(w, `{"id": "28"}`)
+				default:
+					t.Errorf("unexpected request = %v %v", r.Method, r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			attempt, err := c.RetryAttempt("27", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.RetryAttempt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsConflict(err) != tt.wantConflict {
+				t.Errorf("IsConflict() = %v, want %v", IsConflict(err), tt.wantConflict)
+			}
+			if err != nil {
+				return
+			}
+			if attempt.ID != "28" {
+				t.Errorf("Client.RetryAttempt() = %v, want attempt 28", attempt)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("request body = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_RetryAttempt_GeneratedName(t *testing.T) {
+	var got CreateAttempt
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			json.NewDecoder(r.Body).Decode(&got)
+		}
+		fmt.Fprintln(w, `{"id": "27", "workflow": {"name": "test", "id": "2"}, "sessionTime": "2017-06-24T00:00:00+00:00"}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	if _, err := c.RetryAttempt("27", RetryOptions{Mode: ResumeFailed}); err != nil {
+		t.Fatalf("Client.RetryAttempt() error = %v", err)
+	}
+	if len(got.RetryAttemptName) != 36 {
+		t.Errorf("RetryAttemptName = %v, want UUID", got.RetryAttemptName)
+	}
+}
+
+func TestClient_RetryAttempt_LargeIntegerParams(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			b, _ := ioutil.ReadAll(r.Body)
+			got = string(b)
+		}
+		fmt.Fprintln(w, `{"id": "27", "workflow": {"name": "test", "id": "2"}, "sessionTime": "2017-06-24T00:00:00+00:00", "params": {"id": 12345678901234567891, "ratio": 0.1}}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	if _, err := c.RetryAttempt("27", RetryOptions{Name: "retry1"}); err != nil {
+		t.Fatalf("Client.RetryAttempt() error = %v", err)
+	}
+	want := `"params":{"id":12345678901234567891,"ratio":0.1}`
+	if !strings.Contains(got, want) {
+		t.Errorf("request body = %v, want to contain %v", got, want)
+	}
+}
+
+func TestClient_CreateNewAttempt_Params(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []string
+		want    Params
+		wantErr bool
+	}{
+		// Test cases
+		{
+			params: []string{"query=a=b", "n:=1", "obj:={\"a\":[true]}"},
+			want: Params{
+				"query": "a=b",
+				"n":     json.Number("1"),
+				"obj":   map[string]interface{}{"a": []interface{}{true}},
+			},
+		},
+		{
+			name:    "test invalid param",
+			params:  []string{"key"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got CreateAttempt
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decode request body: %v", err)
+				}
+				http.ServeFile(w, r, "testdata/new_attempt.json")
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			_, _, err := c.CreateNewAttempt("2", time.Date(2017, 6, 24, 0, 0, 0, 0, time.UTC), tt.params, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.CreateNewAttempt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got.Params, tt.want) {
+				t.Errorf("request params = %v, want %v", got.Params, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_StartWorkflow(t *testing.T) {
+	sessionTime := time.Date(2017, 6, 24, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		projects     string
+		workflows    string
+		status       int
+		res          string
+		attempts     string
+		wantID       string
+		wantExisting bool
+		wantErr      error
+	}{
+		// Test cases
+		{
+			name:      "test start a workflow",
+			projects:  `{"projects": [{"id": "1", "name": "test"}]}`,
+			workflows: `{"workflows": [{"id": "2", "name": "wf"}]}`,
+			status:    http.StatusOK,
+			res:       `{"id": "27", "sessionTime": "2017-06-24T00:00:00+00:00"}`,
+			wantID:    "27",
+		},
+		{
+			name:         "test conflict returns the attempt in the response",
+			projects:     `{"projects": [{"id": "1", "name": "test"}]}`,
+			workflows:    `{"workflows": [{"id": "2", "name": "wf"}]}`,
+			status:       http.StatusConflict,
+			res:          `{"id": "26", "sessionTime": "2017-06-24T00:00:00+00:00", "done": true}`,
+			wantID:       "26",
+			wantExisting: true,
+		},
+		{
+			name:         "test conflict looks up the attempt",
+			projects:     `{"projects": [{"id": "1", "name": "test"}]}`,
+			workflows:    `{"workflows": [{"id": "2", "name": "wf"}]}`,
+			status:       http.StatusConflict,
+			res:          `{"message": "Session already exists", "status": 409}`,
+			attempts:     `{"attempts": [{"id": "30", "sessionTime": "2017-06-25T00:00:00+00:00"}, {"id": "25", "sessionTime": "2017-06-24T09:00:00+09:00"}]}`,
+			wantID:       "25",
+			wantExisting: true,
+		},
+		{
+			name:      "test conflict without the attempt",
+			projects:  `{"projects": [{"id": "1", "name": "test"}]}`,
+			workflows: `{"workflows": [{"id": "2", "name": "wf"}]}`,
+			status:    http.StatusConflict,
+			res:       `{"message": "Session already exists", "status": 409}`,
+			attempts:  `{"attempts": [{"id": "30", "sessionTime": "2017-06-25T00:00:00+00:00"}]}`,
+			wantErr:   ErrAttemptNotFound,
+		},
+		{
+			name:     "test project not found",
+			projects: `{"projects": []}`,
+			wantErr:  ErrProjectNotFound,
+		},
+		{
+			name:      "test workflow not found",
+			projects:  `{"projects": [{"id": "1", "name": "test"}]}`,
+			workflows: `{"workflows": []}`,
+			wantErr:   ErrWorkflowNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/api/projects":
+					if got := r.URL.Query().Get("name"); got != "test" {
+						t.Errorf("project name = %v, want : %v", got, "test")
+					}
+					fmt.Fprintln(w, tt.projects)
+				case r.URL.Path == "/api/projects/1/workflows":
+					if got := r.URL.Query().Get("name"); got != "wf" {
+						t.Errorf("workflow name = %v, want : %v", got, "wf")
+					}
+					fmt.Fprintln(w, tt.workflows)
+				case r.Method == http.MethodPut && r.URL.Path == "/api/attempts":
+					var ca CreateAttempt
+					if err := json.NewDecoder(r.Body).Decode(&ca); err != nil {
+						t.Errorf("decode request body: %v", err)
+					}
+					want := CreateAttempt{WorkflowID: "2", SessionTime: sessionTime, Params: Params{"n": json.Number("1")}}
+					if !reflect.DeepEqual(ca, want) {
+						t.Errorf("request body = %+v, want %+v", ca, want)
+					}
+					w.WriteHeader(tt.status)
+					fmt.Fprintln(w, tt.res)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/attempts":
+					if got := r.URL.Query().Get("workflow"); got != "wf" {
+						t.Errorf("workflow = %v, want : %v", got, "wf")
+					}
+					fmt.Fprintln(w, tt.attempts)
+				default:
+					t.Errorf("unexpected request = %v %v", r.Method, r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, existing, err := c.StartWorkflow("test", "wf", sessionTime, map[string]interface{}{"n": 1})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.StartWorkflow() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("Client.StartWorkflow() = %v, want attempt %v", got, tt.wantID)
+			}
+			if existing != tt.wantExisting {
+				t.Errorf("Client.StartWorkflow() existing = %v, want %v", existing, tt.wantExisting)
+			}
+		})
+	}
+}
+
+func TestClient_KillAttempts_Pagination(t *testing.T) {
+	base := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	// Attempt i has the session time base + i hours. digdag-server returns the newest attempt first.
+	const total = 250
+	tests := []struct {
+		name      string
+		filter    KillAttemptsFilter
+		wantCount int
+		wantPages []string
+	}{
+		// Test cases
+		{
+			name:      "test all pages",
+			filter:    KillAttemptsFilter{DryRun: true},
+			wantCount: total,
+			wantPages: []string{"", "151", "51"},
+		},
+		{
+			name: "test session time range",
+			filter: KillAttemptsFilter{
+				SessionTimeFrom: base.Add(160 * time.Hour),
+				SessionTimeTo:   base.Add(200 * time.Hour),
+				DryRun:          true,
+			},
+			wantCount: 40,
+			wantPages: []string{"", "151", "51"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var pages []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if got := q.Get("page_size"); got != "100" {
+					t.Errorf("page_size = %v, want : %v", got, "100")
+				}
+				mu.Lock()
+				pages = append(pages, q.Get("last_id"))
+				mu.Unlock()
+
+				last := total + 1
+				if id := q.Get("last_id"); id != "" {
+					fmt.Sscan(id, &last)
+				}
+				var attempts []string
+				for i := last - 1; i > 0 && len(attempts) < 100; i-- {
+					attempts = append(attempts, fmt.Sprintf(`{"id": "%d", "sessionTime": "%s"}`, i, base.Add(time.Duration(i)*time.Hour).Format(time.RFC3339)))
+				}
+				fmt.Fprintf(w, `{"attempts": [%s]}`, strings.Join(attempts, ","))
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			c.Verbose = false
+			got, err := c.KillAttempts(tt.filter)
+			if err != nil {
+				t.Fatalf("Client.KillAttempts() error = %v", err)
+			}
+			if len(got) != tt.wantCount {
+				t.Errorf("Client.KillAttempts() count = %v, want %v", len(got), tt.wantCount)
+			}
+			if !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("last_id = %v, want %v", pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestClient_KillAttempts_OldSessionsFirst(t *testing.T) {
+	// Like after a backfill, the newest attempts have sessions older than the ones to kill
+	base := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	const total = 150
+	sessionTime := func(id int) time.Time {
+		if id > 50 {
+			return base.Add(-time.Duration(id) * time.Hour)
+		}
+		return base.Add(time.Duration(id) * time.Hour)
+	}
+
+	var mu sync.Mutex
+	var killed []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			killed = append(killed, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/attempts/"), "/kill"))
+			mu.Unlock()
+			return
+		}
+		last := total + 1
+		if id := r.URL.Query().Get("last_id"); id != "" {
+			fmt.Sscan(id, &last)
+		}
+		var attempts []string
+		for i := last - 1; i > 0 && len(attempts) < 100; i-- {
+			attempts = append(attempts, fmt.Sprintf(`{"id": "%d", "sessionTime": "%s"}`, i, sessionTime(i).Format(time.RFC3339)))
+		}
+		fmt.Fprintf(w, `{"attempts": [%s]}`, strings.Join(attempts, ","))
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+	c.Verbose = false
+
+	got, err := c.KillAttempts(KillAttemptsFilter{SessionTimeFrom: base.Add(48 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Client.KillAttempts() error = %v", err)
+	}
+	want := []string{"50", "49", "48"}
+	var gotIDs []string
+	for _, attempt := range got {
+		gotIDs = append(gotIDs, attempt.ID)
+	}
+	if !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("Client.KillAttempts() = %v, want %v", gotIDs, want)
+	}
+	if !reflect.DeepEqual(killed, want) {
+		t.Errorf("killed = %v, want %v", killed, want)
+	}
+}
@@ -0,0 +1,40 @@
+package digdag
+
+import (
+	"net/http"
+)
+
+// CredentialsProvider sets credentials on each request to digdag-server.
+// It is called per request (including retries) so that tokens can be refreshed.
+type CredentialsProvider interface {
+	Authenticate(req *http.Request) error
+}
+
+// CredentialsProviderFunc is an adapter to use a function as CredentialsProvider
+type CredentialsProviderFunc func(req *http.Request) error
+
+// Authenticate calls f(req)
+func (f CredentialsProviderFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BasicAuth is the CredentialsProvider for HTTP basic authentication
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Authenticate sets the Authorization header with basic auth
+func (a *BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// BearerToken is the CredentialsProvider for a static bearer token
+type BearerToken string
+
+// Authenticate sets the Authorization header with the bearer token
+func (t BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
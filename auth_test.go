@@ -0,0 +1,89 @@
+package digdag
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Credentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials CredentialsProvider
+		want        string
+	}{
+		// Test cases
+		{
+			name:        "test basic auth",
+			credentials: &BasicAuth{Username: "user", Password: "pass"},
+			want:        "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:        "test bearer token",
+			credentials: BearerToken("token"),
+			want:        "Bearer token",
+		},
+		{
+			name: "test credentials provider func",
+			credentials: CredentialsProviderFunc(func(req *http.Request) error {
+				req.Header.Set("Authorization", "Custom refreshed")
+				return nil
+			}),
+			want: "Custom refreshed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != tt.want {
+					t.Errorf("Authorization = %v, want %v", got, tt.want)
+				}
+				fmt.Fprintln(w, `{"projects":[]}`)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			c.Credentials = tt.credentials
+			if _, err := c.GetProjects(); err != nil {
+				t.Errorf("Client.GetProjects() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_Credentials_PerRequest(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := fmt.Sprintf("Bearer token-%d", calls)
+		if got := r.Header.Get("Authorization"); got != want {
+			t.Errorf("Authorization = %v, want %v", got, want)
+		}
+		fmt.Fprintln(w, `{"projects":[]}`)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+	c.Credentials = CredentialsProviderFunc(func(req *http.Request) error {
+		calls++
+		return BearerToken(fmt.Sprintf("token-%d", calls)).Authenticate(req)
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetProjects(); err != nil {
+			t.Errorf("Client.GetProjects() error = %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("calls = %v, want %v", calls, 2)
+	}
+}
+
+func TestClient_Credentials_Error(t *testing.T) {
+	wantErr := errors.New("token expired")
+	c := newTestClient("http://localhost:65432")
+	c.Credentials = CredentialsProviderFunc(func(req *http.Request) error {
+		return wantErr
+	})
+	if _, err := c.GetProjects(); !errors.Is(err, wantErr) {
+		t.Errorf("Client.GetProjects() error = %v, want %v", err, wantErr)
+	}
+}
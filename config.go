@@ -0,0 +1,206 @@
+package digdag
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Keys of the digdag CLI config file
+const (
+	configKeyEndpoint   = "client.http.endpoint"
+	configKeyHeaders    = "client.http.headers."
+	configKeyBasicAuth  = "client.http.basic_auth"
+	envEndpoint         = "DIGDAG_ENDPOINT"
+	envBasicAuth        = "DIGDAG_BASIC_AUTH"
+	envConfigHome       = "DIGDAG_CONFIG_HOME"
+	envXDGConfigHome    = "XDG_CONFIG_HOME"
+	defaultConfigSubdir = "digdag"
+	defaultConfigFile   = "config"
+)
+
+// Config is the client configuration of the digdag CLI
+type Config struct {
+	Endpoint  string
+	Headers   http.Header
+	BasicAuth *BasicAuth
+}
+
+// DefaultConfigPath returns the path of the digdag CLI config file.
+// It is `$DIGDAG_CONFIG_HOME/config`, `$XDG_CONFIG_HOME/digdag/config` or `~/.config/digdag/config`.
+func DefaultConfigPath() string {
+	if dir := os.Getenv(envConfigHome); dir != "" {
+		return filepath.Join(dir, defaultConfigFile)
+	}
+	if dir := os.Getenv(envXDGConfigHome); dir != "" {
+		return filepath.Join(dir, defaultConfigSubdir, defaultConfigFile)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", defaultConfigSubdir, defaultConfigFile)
+}
+
+// LoadConfig loads the config file like `digdag -c path` and the environment variables.
+// If path is empty, DefaultConfigPath is used and a missing file is not an error.
+// DIGDAG_ENDPOINT and DIGDAG_BASIC_AUTH (user:password) override the file.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{Headers: http.Header{}}
+
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigPath()
+	}
+
+	if path != "" {
+		f, err := os.Open(path)
+		switch {
+		case err == nil:
+			defer f.Close()
+			props, err := parseProperties(f)
+			if err != nil {
+				return nil, err
+			}
+			cfg.apply(props)
+		case explicit || !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+
+	if v := os.Getenv(envEndpoint); v != "" {
+		cfg.Endpoint = v
+	}
+	if v := os.Getenv(envBasicAuth); v != "" {
+		cfg.BasicAuth = parseBasicAuth(v)
+	}
+
+	return cfg, nil
+}
+
+// apply sets the values of the config file
+func (cfg *Config) apply(props map[string]string) {
+	for k, v := range props {
+		switch {
+		case k == configKeyEndpoint:
+			cfg.Endpoint = v
+		case k == configKeyBasicAuth:
+			cfg.BasicAuth = parseBasicAuth(v)
+		case strings.HasPrefix(k, configKeyHeaders):
+			cfg.Headers.Set(strings.TrimPrefix(k, configKeyHeaders), v)
+		}
+	}
+}
+
+// Options returns the options to configure a client with cfg
+func (cfg *Config) Options() []Option {
+	var opts []Option
+	for k, values := range cfg.Headers {
+		for _, v := range values {
+			opts = append(opts, WithHeader(k, v))
+		}
+	}
+	if cfg.BasicAuth != nil {
+		opts = append(opts, WithCredentials(cfg.BasicAuth))
+	}
+	return opts
+}
+
+// NewClientFromConfig return new client configured by LoadConfig(path).
+// opts are applied after the config so that they take precedence.
+func NewClientFromConfig(path string, opts ...Option) (*Client, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(cfg.Endpoint, append(cfg.Options(), opts...)...)
+}
+
+func parseBasicAuth(v string) *BasicAuth {
+	user, pass := v, ""
+	if i := strings.Index(v, ":"); i >= 0 {
+		user, pass = v[:i], v[i+1:]
+	}
+	return &BasicAuth{Username: user, Password: pass}
+}
+
+// parseProperties parses the java properties format used by the digdag CLI
+func parseProperties(r io.Reader) (map[string]string, error) {
+	props := map[string]string{}
+	scanner := bufio.NewScanner(r)
+
+	var logical string
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical == "" && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		// A line ending with an odd number of backslashes continues to the next line
+		if n := len(line) - len(strings.TrimRight(line, `\`)); n%2 == 1 {
+			logical += line[:len(line)-1]
+			continue
+		}
+		logical += line
+
+		key, value := splitProperty(logical)
+		props[unescapeProperty(key)] = unescapeProperty(value)
+		logical = ""
+	}
+	if logical != "" {
+		key, value := splitProperty(logical)
+		props[unescapeProperty(key)] = unescapeProperty(value)
+	}
+
+	return props, scanner.Err()
+}
+
+// splitProperty splits a line into key and value at the first unescaped `=`, `:` or whitespace
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			rest := strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return line[:i], rest
+		}
+	}
+	return line, ""
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package digdag
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		env     map[string]string
+		want    *Config
+		wantErr bool
+	}{
+		// Test cases
+		{
+			name: "test load config file",
+			path: "testdata/config",
+			want: &Config{
+				Endpoint: "https://digdag.example.com",
+				Headers: http.Header{
+					"Authorization":   []string{"Bearer token"},
+					"X-Custom-Header": []string{"hoge"},
+					"X-Long-Header":   []string{"foobar"},
+				},
+				BasicAuth: &BasicAuth{Username: "user", Password: "pa:ss"},
+			},
+		},
+		{
+			name: "test environment variables override config file",
+			path: "testdata/config",
+			env: map[string]string{
+				"DIGDAG_ENDPOINT":   "http://localhost:5432",
+				"DIGDAG_BASIC_AUTH": "foo:bar",
+			},
+			want: &Config{
+				Endpoint: "http://localhost:5432",
+				Headers: http.Header{
+					"Authorization":   []string{"Bearer token"},
+					"X-Custom-Header": []string{"hoge"},
+					"X-Long-Header":   []string{"foobar"},
+				},
+				BasicAuth: &BasicAuth{Username: "foo", Password: "bar"},
+			},
+		},
+		{
+			name: "test missing default config file",
+			env: map[string]string{
+				"DIGDAG_CONFIG_HOME": "testdata/not_exist",
+			},
+			want: &Config{Headers: http.Header{}},
+		},
+		{
+			name:    "test missing config file",
+			path:    "testdata/not_exist",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DIGDAG_ENDPOINT", "")
+			t.Setenv("DIGDAG_BASIC_AUTH", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			got, err := LoadConfig(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultConfigPath(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		// Test cases
+		{
+			name: "test DIGDAG_CONFIG_HOME",
+			env:  map[string]string{"DIGDAG_CONFIG_HOME": "/etc/digdag", "XDG_CONFIG_HOME": "/xdg"},
+			want: filepath.Join("/etc/digdag", "config"),
+		},
+		{
+			name: "test XDG_CONFIG_HOME",
+			env:  map[string]string{"DIGDAG_CONFIG_HOME": "", "XDG_CONFIG_HOME": "/xdg"},
+			want: filepath.Join("/xdg", "digdag", "config"),
+		},
+		{
+			name: "test home directory",
+			env:  map[string]string{"DIGDAG_CONFIG_HOME": "", "XDG_CONFIG_HOME": "", "HOME": "/home/test"},
+			want: filepath.Join("/home/test", ".config", "digdag", "config"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := DefaultConfigPath(); got != tt.want {
+				t.Errorf("DefaultConfigPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Custom-Header"); got != "hoge" {
+			t.Errorf("X-Custom-Header = %v, want %v", got, "hoge")
+		}
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+			t.Errorf("BasicAuth = %v:%v, want %v:%v", user, pass, "user", "pass")
+		}
+		fmt.Fprintln(w, `{"projects":[]}`)
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "config")
+	content := strings.Join([]string{
+		"client.http.endpoint = " + ts.URL,
+		"client.http.headers.X-Custom-Header = hoge",
+		"client.http.basic_auth = user:pass",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DIGDAG_ENDPOINT", "")
+	t.Setenv("DIGDAG_BASIC_AUTH", "")
+
+	c, err := NewClientFromConfig(path, WithUserAgent("test-agent"))
+	if err != nil {
+		t.Fatalf("NewClientFromConfig() error = %v", err)
+	}
+	if c.BaseURL.String() != ts.URL {
+		t.Errorf("BaseURL = %v, want %v", c.BaseURL, ts.URL)
+	}
+	if c.UserAgent != "test-agent" {
+		t.Errorf("UserAgent = %v, want %v", c.UserAgent, "test-agent")
+	}
+	if _, err := c.GetProjects(); err != nil {
+		t.Errorf("Client.GetProjects() error = %v", err)
+	}
+}
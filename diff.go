@@ -0,0 +1,280 @@
+package digdag
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// diffContextLines is the number of context lines in unified diffs
+const diffContextLines = 3
+
+// maxDiffCells limits the size of the table to compute the line diff of a file
+const maxDiffCells = 16 * 1024 * 1024
+
+// DiffStatus is the status of a file between two revisions
+type DiffStatus string
+
+// DiffStatus values
+const (
+	DiffAdded    DiffStatus = "added"
+	DiffRemoved  DiffStatus = "removed"
+	DiffModified DiffStatus = "modified"
+)
+
+// FileDiff is the difference of a file between two revisions
+type FileDiff struct {
+	Name   string
+	Status DiffStatus
+	// Binary is true if the file is not a text file. UnifiedDiff is empty for binary files.
+	Binary      bool
+	UnifiedDiff string
+}
+
+// ProjectDiff is the file-level difference between two revisions of a project
+type ProjectDiff struct {
+	ProjectID    string
+	FromRevision string
+	ToRevision   string
+	Files        []*FileDiff
+}
+
+// String renders the difference as text
+func (d *ProjectDiff) String() string {
+	var b strings.Builder
+	for _, f := range d.Files {
+		if f.Binary {
+			switch f.Status {
+			case DiffAdded:
+				fmt.Fprintf(&b, "Binary file b/%s added\n", f.Name)
+			case DiffRemoved:
+				fmt.Fprintf(&b, "Binary file a/%s removed\n", f.Name)
+			default:
+				fmt.Fprintf(&b, "Binary files a/%s and b/%s differ\n", f.Name, f.Name)
+			}
+			continue
+		}
+		b.WriteString(f.UnifiedDiff)
+	}
+	return b.String()
+}
+
+// diffFiles compares two sets of files and returns the differences sorted by name
+func diffFiles(from, to map[string][]byte) []*FileDiff {
+	names := map[string]bool{}
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var diffs []*FileDiff
+	for _, name := range sorted {
+		a, inFrom := from[name]
+		b, inTo := to[name]
+
+		fd := &FileDiff{Name: name}
+		switch {
+		case !inFrom:
+			fd.Status = DiffAdded
+		case !inTo:
+			fd.Status = DiffRemoved
+		case bytes.Equal(a, b):
+			continue
+		default:
+			fd.Status = DiffModified
+		}
+
+		if isBinary(a) || isBinary(b) {
+			fd.Binary = true
+		} else {
+			fromName, toName := "a/"+name, "b/"+name
+			if !inFrom {
+				fromName = "/dev/null"
+			}
+			if !inTo {
+				toName = "/dev/null"
+			}
+			fd.UnifiedDiff = unifiedDiff(fromName, toName, string(a), string(b))
+		}
+		diffs = append(diffs, fd)
+	}
+	return diffs
+}
+
+func isBinary(b []byte) bool {
+	return bytes.IndexByte(b, 0) >= 0 || !utf8.Valid(b)
+}
+
+// splitLines splits s into lines keeping the trailing newline of each line
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// diffLines returns the edit script from a to b based on the longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	// Trim the common prefix and suffix to keep the table small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, l := range a[:prefix] {
+		ops = append(ops, diffOp{' ', l})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(ma), len(mb)
+	if (n+1)*(m+1) > maxDiffCells {
+		// Too large to compare line by line
+		for _, l := range ma {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range mb {
+			ops = append(ops, diffOp{'+', l})
+		}
+	} else {
+		// lcs[i][j] is the length of the LCS of ma[i:] and mb[j:]
+		lcs := make([][]int, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && ma[i] == mb[j]:
+				ops = append(ops, diffOp{' ', ma[i]})
+				i++
+				j++
+			case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, diffOp{'-', ma[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', mb[j]})
+				j++
+			}
+		}
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
+
+// unifiedDiff returns the unified diff from a to b
+func unifiedDiff(fromName, toName, a, b string) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	// aLine[k] and bLine[k] are the numbers of lines of a and b before ops[k]
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.kind != '+' {
+			aLine[k+1]++
+		}
+		if op.kind != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+
+		// Extend the hunk while changes are close enough to share context lines
+		start := k - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContextLines {
+				break
+			}
+			end = next
+		}
+		stop := end + diffContextLines
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		aStart, aCount := aLine[start], aLine[stop]-aLine[start]
+		bStart, bCount := bLine[start], bLine[stop]-bLine[start]
+		if aCount > 0 {
+			aStart++
+		}
+		if bCount > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, op := range ops[start:stop] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		k = stop
+	}
+
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,107 @@
+package digdag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_unifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		a    string
+		b    string
+		want string
+	}{
+		// Test cases
+		{
+			name: "test modified line",
+			from: "a/test.dig",
+			to:   "b/test.dig",
+			a:    "timezone: UTC\n\n+setup:\n  echo>: start\n\n+main:\n  sh>: run.sh\n\n+teardown:\n  echo>: finish\n",
+			b:    "timezone: Asia/Tokyo\n\n+setup:\n  echo>: start\n\n+main:\n  sh>: run.sh\n\n+teardown:\n  echo>: done\n",
+			want: `--- a/test.dig
++++ b/test.dig
+@@ -1,4 +1,4 @@
+-timezone: UTC
++timezone: Asia/Tokyo
+ 
+ +setup:
+   echo>: start
+@@ -7,4 +7,4 @@
+   sh>: run.sh
+ 
+ +teardown:
+-  echo>: finish
++  echo>: done
+`,
+		},
+		{
+			name: "test added file",
+			from: "/dev/null",
+			to:   "b/query.sql",
+			a:    "",
+			b:    "SELECT 1\nFROM t",
+			want: `--- /dev/null
++++ b/query.sql
+@@ -0,0 +1,2 @@
++SELECT 1
++FROM t
+\ No newline at end of file
+`,
+		},
+		{
+			name: "test removed file",
+			from: "a/query.sql",
+			to:   "/dev/null",
+			a:    "SELECT 1\n",
+			b:    "",
+			want: `--- a/query.sql
++++ /dev/null
+@@ -1 +0,0 @@
+-SELECT 1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff(tt.from, tt.to, tt.a, tt.b); got != tt.want {
+				t.Errorf("unifiedDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_diffFiles(t *testing.T) {
+	from := map[string][]byte{
+		"test.dig":      []byte("+test:\n  echo>: test\n"),
+		"query.sql":     []byte("SELECT 1\n"),
+		"unchanged.sql": []byte("SELECT 2\n"),
+		"data.bin":      {0x00, 0x01},
+	}
+	to := map[string][]byte{
+		"test.dig":      []byte("+test:\n  echo>: changed\n"),
+		"unchanged.sql": []byte("SELECT 2\n"),
+		"data.bin":      {0x00, 0x02},
+		"new.sql":       []byte("SELECT 3\n"),
+	}
+
+	got := diffFiles(from, to)
+	want := []*FileDiff{
+		{Name: "data.bin", Status: DiffModified, Binary: true},
+		{Name: "new.sql", Status: DiffAdded, UnifiedDiff: "--- /dev/null\n+++ b/new.sql\n@@ -0,0 +1 @@\n+SELECT 3\n"},
+		{Name: "query.sql", Status: DiffRemoved, UnifiedDiff: "--- a/query.sql\n+++ /dev/null\n@@ -1 +0,0 @@\n-SELECT 1\n"},
+		{Name: "test.dig", Status: DiffModified, UnifiedDiff: "--- a/test.dig\n+++ b/test.dig\n@@ -1,2 +1,2 @@\n +test:\n-  echo>: test\n+  echo>: changed\n"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffFiles() = %v, want %v", got, want)
+	}
+
+	d := &ProjectDiff{Files: got}
+	wantText := "Binary files a/data.bin and b/data.bin differ\n" +
+		want[1].UnifiedDiff + want[2].UnifiedDiff + want[3].UnifiedDiff
+	if d.String() != wantText {
+		t.Errorf("ProjectDiff.String() = %v, want %v", d.String(), wantText)
+	}
+}
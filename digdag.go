@@ -1,16 +1,17 @@
 package digdag
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"path"
 	"runtime"
+	"time"
 )
 
 const (
@@ -24,28 +25,40 @@ type Client struct {
 	HTTPClient    *http.Client
 	UserAgent     string
 	CustomHeaders http.Header
+	Credentials   CredentialsProvider
+	RetryPolicy   *RetryPolicy
+	Middlewares   []Middleware
+	Metrics       MetricsRecorder
 
 	Verbose bool
+	Logger  Logger
+
+	// options applied to HTTPClient after all options of NewClient
+	httpClientOptions []Option
 }
 
 // RequestOpts is the list of options to pass to the request
 type RequestOpts struct {
-	Params map[string]string
-	// Headers map[string]string
-	Body io.Reader
+	Params  map[string]string
+	Headers map[string]string
+	Body    io.Reader
+	// Idempotent marks a request with a side effect as safe to replay on retry
+	Idempotent bool
+	// SensitiveBody prevents the request body from being logged
+	SensitiveBody bool
 }
 
-//  default UserAgent
+// default UserAgent
 var defaultUserAgent = fmt.Sprintf("DigdagGoClient/%s (%s)", version, runtime.Version())
 
 // NewClient return new client for digdag
-func NewClient(urlStr string, verbose bool) (*Client, error) {
-	if urlStr == "" {
+func NewClient(baseURL string, opts ...Option) (*Client, error) {
+	if baseURL == "" {
 		// Set default
-		urlStr = defaultBaseURL
+		baseURL = defaultBaseURL
 	}
 
-	parsedURL, err := url.Parse(urlStr)
+	parsedURL, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
 	}
@@ -56,15 +69,37 @@ func NewClient(urlStr string, verbose bool) (*Client, error) {
 
 		UserAgent:     defaultUserAgent,
 		CustomHeaders: http.Header{},
+	}
 
-		Verbose: verbose,
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+	for _, opt := range client.httpClientOptions {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
 	}
+	client.httpClientOptions = nil
 
-	return client, err
+	return client, nil
+}
+
+// NewClientWithVerbose return new client for digdag.
+//
+// Deprecated: Use NewClient with WithVerbose instead.
+func NewClientWithVerbose(urlStr string, verbose bool) (*Client, error) {
+	return NewClient(urlStr, WithVerbose(verbose))
 }
 
 // NewRequest request to digdag-server
 func (c *Client) NewRequest(method, spath string, ro *RequestOpts) (resp *http.Response, err error) {
+	return c.NewRequestContext(context.Background(), method, spath, ro)
+}
+
+// NewRequestContext request to digdag-server with the given context
+func (c *Client) NewRequestContext(ctx context.Context, method, spath string, ro *RequestOpts) (resp *http.Response, err error) {
 	u := *c.BaseURL
 	u.Path = path.Join(c.BaseURL.Path, spath)
 
@@ -79,46 +114,137 @@ func (c *Client) NewRequest(method, spath string, ro *RequestOpts) (resp *http.R
 	}
 	u.RawQuery = params.Encode()
 
-	req, err := http.NewRequest(method, u.String(), ro.Body)
-	if err != nil {
-		return nil, err
-	}
+	retry := c.RetryPolicy != nil && c.RetryPolicy.MaxRetries > 0 && isRetryableRequest(method, ro)
 
-	// Set headers
-	if ro.Body != nil {
-		req.Header.Set("Content-Type", "application/json")
+	// Buffer the body so that it can be replayed on retry
+	var body []byte
+	if retry && ro.Body != nil {
+		body, err = ioutil.ReadAll(ro.Body)
+		if err != nil {
+			return nil, err
+		}
 	}
-	req.Header.Set("User-Agent", c.UserAgent)
 
-	// Set custom headers
-	for header, values := range c.CustomHeaders {
-		for _, v := range values {
-			req.Header.Set(header, v)
+	for n := 0; ; n++ {
+		reqBody := ro.Body
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+		if err != nil {
+			return nil, err
+		}
+
+		// Set headers
+		if ro.Body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("User-Agent", c.UserAgent)
+		for k, v := range ro.Headers {
+			req.Header.Set(k, v)
+		}
+
+		// Set custom headers
+		for header, values := range c.CustomHeaders {
+			for _, v := range values {
+				req.Header.Set(header, v)
+			}
+		}
+
+		// Set credentials
+		if c.Credentials != nil {
+			if err := c.Credentials.Authenticate(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err = c.do(req, ro, n)
+		if !retry || n >= c.RetryPolicy.MaxRetries || !c.RetryPolicy.shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		wait := c.RetryPolicy.backoff(n, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if c.Metrics != nil {
+			c.Metrics.IncRetry(endpointPattern(u.Path), method)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
 		}
 	}
+}
 
-	if c.Verbose {
-		dump, err := httputil.DumpRequestOut(req, true)
-		if err == nil {
-			log.Printf("%s", dump)
+// do sends a single request and converts error responses into *APIError
+func (c *Client) do(req *http.Request, ro *RequestOpts, retry int) (*http.Response, error) {
+	logger := c.logger()
+	if logger != nil && c.Verbose {
+		body := "REDACTED"
+		if !ro.SensitiveBody {
+			body = requestBodyForLog(req)
 		}
+		logger.Debug("digdag request",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"header", redactHeader(req.Header),
+			"body", body,
+		)
 	}
 
-	client := c.HTTPClient
-	resp, err = client.Do(req)
+	client := c.httpClient()
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := time.Since(start)
+	if c.Metrics != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		c.Metrics.ObserveRequest(endpointPattern(req.URL.Path), req.Method, status, latency)
+	}
 	if err != nil {
+		if logger != nil {
+			logger.Warn("digdag request failed",
+				"method", req.Method,
+				"path", req.URL.Path,
+				"latency", latency,
+				"retry", retry,
+				"error", err,
+			)
+		}
 		return nil, err
 	}
 
-	if c.Verbose {
-		dump, err := httputil.DumpResponse(resp, true)
-		if err == nil {
-			log.Printf("%s", dump)
+	if logger != nil {
+		args := []interface{}{
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", resp.StatusCode,
+			"latency", latency,
+			"retry", retry,
+		}
+		if c.Verbose {
+			args = append(args, "header", redactHeader(resp.Header), "body", responseBodyForLog(resp))
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			logger.Warn("digdag response", args...)
+		} else {
+			logger.Debug("digdag response", args...)
 		}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		return resp, fmt.Errorf("Failed to request: %s", resp.Status)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return resp, err
+		}
+		// Keep the body readable for callers
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return resp, newAPIError(req, resp, body)
 	}
 
 	return resp, nil
@@ -1,15 +1,19 @@
 package digdag
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func newTestClient(urlStr string) *Client {
-	client, _ := NewClient(urlStr, true)
+	client, _ := NewClient(urlStr, WithVerbose(true))
 	client.CustomHeaders.Set("X-Custom-Header", "hoge")
 	return client
 }
@@ -19,7 +23,7 @@ func readFile(filename string) string {
 	return string(content)
 }
 
-func TestNewClient(t *testing.T) {
+func TestNewClientWithVerbose(t *testing.T) {
 	parsedURL, _ := url.Parse(defaultBaseURL)
 	type args struct {
 		urlStr  string
@@ -47,13 +51,60 @@ func TestNewClient(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewClient(tt.args.urlStr, tt.args.verbose)
+			got, err := NewClientWithVerbose(tt.args.urlStr, tt.args.verbose)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("NewClientWithVerbose() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewClient() = %v, want %v", got, tt.want)
+				t.Errorf("NewClientWithVerbose() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_NewRequestContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name: "test context is cancelled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "test context deadline exceeded",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-done:
+				case <-r.Context().Done():
+				}
+			}))
+			defer ts.Close()
+			defer close(done)
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			c := newTestClient(ts.URL)
+			_, err := c.NewRequestContext(ctx, http.MethodGet, "/api/projects", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.NewRequestContext() error = %v, want %v", err, tt.wantErr)
 			}
 		})
 	}
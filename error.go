@@ -0,0 +1,133 @@
+package digdag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is the error returned when digdag-server responds with an error status
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+	Body       []byte
+}
+
+// apiErrorBody is struct for the error json returned by digdag-server
+type apiErrorBody struct {
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Body:       body,
+	}
+
+	var eb apiErrorBody
+	if err := json.Unmarshal(body, &eb); err == nil {
+		e.Message = eb.Message
+	}
+
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Failed to request: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError with status 409
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err is an APIError with status 401
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func hasStatus(err error, code int) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == code
+	}
+	return false
+}
+
+// Errors returned by the lookup helpers when nothing matches.
+// Use errors.Is to check for them and errors.As with *NotFoundError to get the resource name.
+var (
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrWorkflowNotFound = errors.New("workflow not found")
+	ErrNoSessions       = errors.New("sessions not found")
+	ErrAttemptNotFound  = errors.New("attempts does not exist")
+	ErrSessionNotFound  = errors.New("session not found")
+	ErrTaskNotFound     = errors.New("task result not found")
+	ErrLogNotFound      = errors.New("task log not found")
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrTaskFailed       = errors.New("task failed")
+)
+
+// NotFoundError wraps a not-found sentinel error with the name of the resource
+type NotFoundError struct {
+	Name string
+	Err  error
+	// Cause is the *APIError of the 404 response when the resource is looked up by ID
+	Cause error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.Name)
+}
+
+// Is reports whether target is the sentinel error
+func (e *NotFoundError) Is(target error) bool {
+	return target == e.Err
+}
+
+// Unwrap returns Cause if any, otherwise the sentinel error
+func (e *NotFoundError) Unwrap() error {
+	if e.Cause != nil {
+		return e.Cause
+	}
+	return e.Err
+}
+
+// notFound converts err of a 404 response into *NotFoundError of sentinel
+func notFound(err error, name string, sentinel error) error {
+	if IsNotFound(err) {
+		return &NotFoundError{Name: name, Err: sentinel, Cause: err}
+	}
+	return err
+}
+
+// TaskFailedError is returned when a task finished with a state other than success.
+// It matches ErrTaskFailed with errors.Is.
+type TaskFailedError struct {
+	TaskName string
+	State    string
+}
+
+func (e *TaskFailedError) Error() string {
+	return fmt.Sprintf("task `%s` state is %s", e.TaskName, e.State)
+}
+
+// Unwrap returns ErrTaskFailed
+func (e *TaskFailedError) Unwrap() error {
+	return ErrTaskFailed
+}
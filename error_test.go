@@ -0,0 +1,268 @@
+package digdag
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_NewRequest_APIError(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		res              string
+		wantMessage      string
+		wantNotFound     bool
+		wantConflict     bool
+		wantUnauthorized bool
+	}{
+		// Test cases
+		{
+			name:         "test not found",
+			status:       http.StatusNotFound,
+			res:          `{"message":"Resource does not exist: project id=999","status":404}`,
+			wantMessage:  "Resource does not exist: project id=999",
+			wantNotFound: true,
+		},
+		{
+			name:         "test conflict",
+			status:       http.StatusConflict,
+			res:          `{"message":"Session already exists","status":409}`,
+			wantMessage:  "Session already exists",
+			wantConflict: true,
+		},
+		{
+			name:             "test unauthorized with non json body",
+			status:           http.StatusUnauthorized,
+			res:              `Unauthorized`,
+			wantUnauthorized: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			_, err := c.NewRequest(http.MethodGet, "/api/projects/999", nil)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Client.NewRequest() error = %v, want *APIError", err)
+			}
+			want := &APIError{
+				StatusCode: tt.status,
+				Method:     http.MethodGet,
+				URL:        ts.URL + "/api/projects/999",
+				Message:    tt.wantMessage,
+				Body:       []byte(tt.res),
+			}
+			if !reflect.DeepEqual(apiErr, want) {
+				t.Errorf("Client.NewRequest() error = %#v, want %#v", apiErr, want)
+			}
+			if got := IsNotFound(err); got != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.wantNotFound)
+			}
+			if got := IsConflict(err); got != tt.wantConflict {
+				t.Errorf("IsConflict() = %v, want %v", got, tt.wantConflict)
+			}
+			if got := IsUnauthorized(err); got != tt.wantUnauthorized {
+				t.Errorf("IsUnauthorized() = %v, want %v", got, tt.wantUnauthorized)
+			}
+		})
+	}
+}
+
+func TestIsNotFound_Wrapped(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: http.StatusNotFound})
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound() = false, want true")
+	}
+	if IsNotFound(errors.New("not an api error")) {
+		t.Errorf("IsNotFound() = true, want false")
+	}
+}
+
+func TestClient_NotFoundErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		res      string
+		call     func(c *Client) error
+		wantErr  error
+		wantName string
+	}{
+		// Test cases
+		{
+			name:     "test project not found",
+			res:      `{"projects":[]}`,
+			call:     func(c *Client) error { _, err := c.GetProject("hoge"); return err },
+			wantErr:  ErrProjectNotFound,
+			wantName: "hoge",
+		},
+		{
+			name:     "test workflow not found",
+			res:      `{"workflows":[]}`,
+			call:     func(c *Client) error { _, err := c.GetWorkflow("1", "hoge"); return err },
+			wantErr:  ErrWorkflowNotFound,
+			wantName: "hoge",
+		},
+		{
+			name:     "test sessions not found",
+			res:      `{"sessions":[]}`,
+			call:     func(c *Client) error { _, err := c.GetProjectWorkflowSessions("1", "hoge"); return err },
+			wantErr:  ErrNoSessions,
+			wantName: "hoge",
+		},
+		{
+			name: "test attempts not found",
+			res:  `{"attempts":[]}`,
+			call: func(c *Client) error {
+				_, err := c.GetAttemptIDs("test", "hoge", time.Date(2017, 6, 24, 0, 0, 0, 0, time.UTC))
+				return err
+			},
+			wantErr:  ErrAttemptNotFound,
+			wantName: "project=test workflow=hoge",
+		},
+		{
+			name:     "test log files not found",
+			res:      `{"files":[]}`,
+			call:     func(c *Client) error { _, err := c.GetLogFiles("11"); return err },
+			wantErr:  ErrLogNotFound,
+			wantName: "11",
+		},
+		{
+			name:     "test task result not found",
+			res:      `{"tasks":[]}`,
+			call:     func(c *Client) error { _, err := c.GetTaskResult([]string{"11"}, "+hoge"); return err },
+			wantErr:  ErrTaskNotFound,
+			wantName: "+hoge",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			err := tt.call(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			var nfErr *NotFoundError
+			if !errors.As(err, &nfErr) {
+				t.Fatalf("error = %v, want *NotFoundError", err)
+			}
+			if nfErr.Name != tt.wantName {
+				t.Errorf("NotFoundError.Name = %v, want %v", nfErr.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestClient_NotFoundErrorsByID(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		call         func(c *Client) error
+		wantErr      error
+		wantNotFound bool
+	}{
+		// Test cases
+		{
+			name:         "test project not found",
+			status:       http.StatusNotFound,
+			call:         func(c *Client) error { _, err := c.GetProjectByID("999"); return err },
+			wantErr:      ErrProjectNotFound,
+			wantNotFound: true,
+		},
+		{
+			name:         "test workflow not found",
+			status:       http.StatusNotFound,
+			call:         func(c *Client) error { _, err := c.GetWorkflowByID("999"); return err },
+			wantErr:      ErrWorkflowNotFound,
+			wantNotFound: true,
+		},
+		{
+			name:         "test session not found",
+			status:       http.StatusNotFound,
+			call:         func(c *Client) error { _, err := c.GetSession("999"); return err },
+			wantErr:      ErrSessionNotFound,
+			wantNotFound: true,
+		},
+		{
+			name:         "test attempt not found",
+			status:       http.StatusNotFound,
+			call:         func(c *Client) error { _, err := c.GetAttempt("999"); return err },
+			wantErr:      ErrAttemptNotFound,
+			wantNotFound: true,
+		},
+		{
+			name:         "test schedule not found",
+			status:       http.StatusNotFound,
+			call:         func(c *Client) error { _, err := c.GetSchedule("999"); return err },
+			wantErr:      ErrScheduleNotFound,
+			wantNotFound: true,
+		},
+		{
+			name:    "test server error",
+			status:  http.StatusInternalServerError,
+			call:    func(c *Client) error { _, err := c.GetAttempt("999"); return err },
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, `{"message":"Resource does not exist","status":404}`)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			err := tt.call(c)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			var nfErr *NotFoundError
+			if errors.As(err, &nfErr) != tt.wantNotFound {
+				t.Errorf("error = %v, want *NotFoundError %v", err, tt.wantNotFound)
+			}
+			if tt.wantNotFound && nfErr.Name != "id=999" {
+				t.Errorf("NotFoundError.Name = %v, want %v", nfErr.Name, "id=999")
+			}
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", IsNotFound(err), tt.wantNotFound)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("error = %v, want *APIError with status %v", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestClient_GetTaskResult_TaskFailedError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"tasks":[{"id":"277","fullName":"+test+test1","state":"error"}]}`)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+	_, err := c.GetTaskResult([]string{"11"}, "+test+test1")
+	if !errors.Is(err, ErrTaskFailed) {
+		t.Fatalf("Client.GetTaskResult() error = %v, want %v", err, ErrTaskFailed)
+	}
+	var tfErr *TaskFailedError
+	if !errors.As(err, &tfErr) {
+		t.Fatalf("Client.GetTaskResult() error = %v, want *TaskFailedError", err)
+	}
+	want := &TaskFailedError{TaskName: "+test+test1", State: "error"}
+	if !reflect.DeepEqual(tfErr, want) {
+		t.Errorf("Client.GetTaskResult() error = %v, want %v", tfErr, want)
+	}
+}
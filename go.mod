@@ -1,8 +1,11 @@
 module github.com/szyn/digdag-go-client
 
+go 1.15
+
 require (
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/kr/pty v1.1.4 // indirect
 	github.com/satori/go.uuid v1.2.0
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
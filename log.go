@@ -1,7 +1,7 @@
 package digdag
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"net/http"
 
@@ -26,10 +26,15 @@ type LogFile struct {
 
 // GetLogFiles to get logfile list
 func (c *Client) GetLogFiles(attemptID string) ([]*LogFile, error) {
+	return c.GetLogFilesContext(context.Background(), attemptID)
+}
+
+// GetLogFilesContext to get logfile list with the given context
+func (c *Client) GetLogFilesContext(ctx context.Context, attemptID string) ([]*LogFile, error) {
 	spath := fmt.Sprintf("/api/logs/%s/files", attemptID)
 
 	var logFiles *logFiles
-	resp, err := c.NewRequest(http.MethodGet, spath, nil)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
 
 	if err != nil {
 		return nil, err
@@ -41,7 +46,7 @@ func (c *Client) GetLogFiles(attemptID string) ([]*LogFile, error) {
 
 	// if any logFiles not found
 	if len(logFiles.Files) == 0 {
-		return nil, errors.New("task log not found")
+		return nil, &NotFoundError{Name: attemptID, Err: ErrLogNotFound}
 	}
 
 	return logFiles.Files, err
@@ -49,7 +54,12 @@ func (c *Client) GetLogFiles(attemptID string) ([]*LogFile, error) {
 
 // GetLogFileResult to get logfile result
 func (c *Client) GetLogFileResult(attemptID, taskName string) (*LogFile, error) {
-	logFiles, err := c.GetLogFiles(attemptID)
+	return c.GetLogFileResultContext(context.Background(), attemptID, taskName)
+}
+
+// GetLogFileResultContext to get logfile result with the given context
+func (c *Client) GetLogFileResultContext(ctx context.Context, attemptID, taskName string) (*LogFile, error) {
+	logFiles, err := c.GetLogFilesContext(ctx, attemptID)
 	if err != nil {
 		return nil, err
 	}
@@ -58,17 +68,24 @@ func (c *Client) GetLogFileResult(attemptID, taskName string) (*LogFile, error)
 		if logFiles[l].TaskName == taskName {
 			return logFiles[l], nil
 		}
-		err = errors.New("task log `" + taskName + "` not found")
 	}
 
-	return nil, err
+	return nil, &NotFoundError{Name: taskName, Err: ErrLogNotFound}
 }
 
 // GetLogText to get logtext
 func (c *Client) GetLogText(attemptID, fileName string) (string, error) {
+	return c.GetLogTextContext(context.Background(), attemptID, fileName)
+}
+
+// GetLogTextContext to get logtext with the given context
+func (c *Client) GetLogTextContext(ctx context.Context, attemptID, fileName string) (string, error) {
 	spath := fmt.Sprintf("/api/logs/%s/files/%s", attemptID, fileName)
 
-	resp, err := c.NewRequest(http.MethodGet, spath, nil)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return "", err
+	}
 
 	gztext, err := respToString(resp)
 	if err != nil {
@@ -76,10 +93,10 @@ func (c *Client) GetLogText(attemptID, fileName string) (string, error) {
 	}
 
 	gr, err := gzip.NewReader(bytes.NewBufferString(gztext))
-	defer gr.Close()
 	if err != nil {
 		return "", err
 	}
+	defer gr.Close()
 
 	data, err := ioutil.ReadAll(gr)
 	return string(data), err
@@ -1,6 +1,8 @@
 package digdag
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -207,3 +209,22 @@ func TestClient_GetLogText(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetLogTextContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/+test+test@5a54eea130ef7740.73100@test.local.log.gz")
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := newTestClient(ts.URL)
+	got, err := c.GetLogTextContext(ctx, "11", "+test+test@5a54eea130ef7740.73100@test.local.log.gz")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Client.GetLogTextContext() error = %v, want %v", err, context.Canceled)
+	}
+	if got != "" {
+		t.Errorf("Client.GetLogTextContext() = %v, want empty", got)
+	}
+}
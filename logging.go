@@ -0,0 +1,128 @@
+package digdag
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLogBodySize is the maximum size of a body written to the log
+const maxLogBodySize = 1024
+
+// redactedHeaders is the list of headers whose values are not written to the log
+var redactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Logger is the interface for structured logging of requests.
+// args are alternating keys and values. *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// stdLogger is the Logger writing to the standard logger, used when Verbose is set without Logger
+type stdLogger struct{}
+
+func (stdLogger) Debug(msg string, args ...interface{}) { stdLog("DEBUG", msg, args) }
+func (stdLogger) Info(msg string, args ...interface{})  { stdLog("INFO", msg, args) }
+func (stdLogger) Warn(msg string, args ...interface{})  { stdLog("WARN", msg, args) }
+func (stdLogger) Error(msg string, args ...interface{}) { stdLog("ERROR", msg, args) }
+
+func stdLog(level, msg string, args []interface{}) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", level, msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%q", args[i], fmt.Sprint(args[i+1]))
+		} else {
+			fmt.Fprintf(&b, " %q", fmt.Sprint(args[i]))
+		}
+	}
+	log.Print(b.String())
+}
+
+// logger returns the Logger to use, or nil if logging is disabled
+func (c *Client) logger() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	if c.Verbose {
+		return stdLogger{}
+	}
+	return nil
+}
+
+// redactHeader returns a copy of h with credentials redacted
+func redactHeader(h http.Header) http.Header {
+	redacted := h.Clone()
+	for _, k := range redactedHeaders {
+		if _, ok := redacted[k]; ok {
+			redacted[k] = []string{"REDACTED"}
+		}
+	}
+	return redacted
+}
+
+// requestBodyForLog returns the truncated request body without consuming it
+func requestBodyForLog(req *http.Request) string {
+	if req.Body == nil || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	peek, _ := ioutil.ReadAll(io.LimitReader(body, maxLogBodySize+1))
+	return truncateBody(peek)
+}
+
+// responseBodyForLog returns the truncated response body and keeps resp.Body readable
+func responseBodyForLog(resp *http.Response) string {
+	peek, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxLogBodySize+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	if err != nil {
+		return ""
+	}
+	return truncateBody(peek)
+}
+
+func truncateBody(body []byte) string {
+	truncated := len(body) > maxLogBodySize
+	if truncated {
+		body = body[:maxLogBodySize]
+	}
+	if !utf8.Valid(body) && !(truncated && utf8.Valid(trimIncompleteRune(body))) {
+		return "<binary body>"
+	}
+	if truncated {
+		return string(trimIncompleteRune(body)) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// trimIncompleteRune drops a multi-byte character cut off at the end of b
+func trimIncompleteRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		if utf8.Valid(b) {
+			return b
+		}
+		b = b[:len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,9 @@
+//go:build go1.21
+// +build go1.21
+
+package digdag
+
+import "log/slog"
+
+// *slog.Logger must satisfy Logger
+var _ Logger = (*slog.Logger)(nil)
@@ -0,0 +1,146 @@
+package digdag
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testLogEntry struct {
+	level string
+	msg   string
+	args  map[string]interface{}
+}
+
+// testLogger is the Logger recording log entries
+type testLogger struct {
+	mu      sync.Mutex
+	entries []testLogEntry
+}
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := testLogEntry{level: level, msg: msg, args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		e.args[fmt.Sprint(args[i])] = args[i+1]
+	}
+	l.entries = append(l.entries, e)
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args) }
+
+func TestClient_Logger(t *testing.T) {
+	tests := []struct {
+		name        string
+		verbose     bool
+		status      int
+		wantLevel   string
+		wantEntries int
+	}{
+		// Test cases
+		{
+			name:        "test log response",
+			status:      http.StatusOK,
+			wantLevel:   "DEBUG",
+			wantEntries: 1,
+		},
+		{
+			name:        "test log error response",
+			status:      http.StatusNotFound,
+			wantLevel:   "WARN",
+			wantEntries: 1,
+		},
+		{
+			name:        "test log request and response with verbose",
+			verbose:     true,
+			status:      http.StatusOK,
+			wantLevel:   "DEBUG",
+			wantEntries: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Set-Cookie", "session=secret")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, `{"projects":[]}`)
+			}))
+			defer ts.Close()
+
+			logger := &testLogger{}
+			c, _ := NewClient(ts.URL, WithLogger(logger), WithVerbose(tt.verbose), WithBearerToken("secret"))
+			c.GetProjects()
+
+			if len(logger.entries) != tt.wantEntries {
+				t.Fatalf("entries = %v, want %v", len(logger.entries), tt.wantEntries)
+			}
+			e := logger.entries[len(logger.entries)-1]
+			if e.level != tt.wantLevel {
+				t.Errorf("level = %v, want %v", e.level, tt.wantLevel)
+			}
+			if e.args["method"] != http.MethodGet || e.args["path"] != "/api/projects" || e.args["status"] != tt.status || e.args["retry"] != 0 {
+				t.Errorf("args = %v", e.args)
+			}
+			if _, ok := e.args["latency"]; !ok {
+				t.Errorf("args = %v, want latency", e.args)
+			}
+			if !tt.verbose {
+				return
+			}
+			if got := e.args["body"]; got != `{"projects":[]}` {
+				t.Errorf("body = %v, want %v", got, `{"projects":[]}`)
+			}
+			req := logger.entries[0]
+			if got := req.args["header"].(http.Header).Get("Authorization"); got != "REDACTED" {
+				t.Errorf("Authorization = %v, want REDACTED", got)
+			}
+			if got := e.args["header"].(http.Header).Get("Set-Cookie"); got != "REDACTED" {
+				t.Errorf("Set-Cookie = %v, want REDACTED", got)
+			}
+		})
+	}
+}
+
+func Test_truncateBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+		want string
+	}{
+		// Test cases
+		{
+			name: "test short body",
+			body: []byte(`{"id":"1"}`),
+			want: `{"id":"1"}`,
+		},
+		{
+			name: "test long body",
+			body: []byte(strings.Repeat("a", maxLogBodySize+1)),
+			want: strings.Repeat("a", maxLogBodySize) + "...(truncated)",
+		},
+		{
+			name: "test multi-byte character cut off",
+			body: []byte(strings.Repeat("a", maxLogBodySize-1) + "あ"),
+			want: strings.Repeat("a", maxLogBodySize-1) + "...(truncated)",
+		},
+		{
+			name: "test gzip body",
+			body: []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe},
+			want: "<binary body>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateBody(tt.body); got != tt.want {
+				t.Errorf("truncateBody() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
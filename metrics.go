@@ -0,0 +1,212 @@
+package digdag
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsRecorder records metrics of requests to digdag-server.
+// endpoint is the request path with IDs replaced, e.g. `/api/attempts/{id}/tasks`.
+type MetricsRecorder interface {
+	// ObserveRequest is called after each HTTP round trip. status is 0 on transport errors.
+	ObserveRequest(endpoint, method string, status int, latency time.Duration)
+	// IncRetry is called before each retry
+	IncRetry(endpoint, method string)
+}
+
+// DefaultLatencyBuckets is the default buckets of the latency histogram in seconds
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// endpointPattern replaces IDs and names in path to keep the number of endpoints small
+func endpointPattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if i > 0 && (segments[i-1] == "files" || segments[i-1] == "secrets") {
+			segments[i] = "{name}"
+			continue
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type metricsKey struct {
+	endpoint string
+	method   string
+	code     string
+}
+
+type histogram struct {
+	counts []uint64 // cumulative counts per bucket
+	count  uint64
+	sum    float64
+}
+
+// MetricsCollector is the in-process MetricsRecorder rendering the Prometheus text format
+type MetricsCollector struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[metricsKey]uint64
+	errors    map[metricsKey]uint64
+	retries   map[metricsKey]uint64
+	latencies map[metricsKey]*histogram
+}
+
+// NewMetricsCollector return new collector. If buckets is empty, DefaultLatencyBuckets is used.
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+
+	return &MetricsCollector{
+		buckets:   b,
+		requests:  map[metricsKey]uint64{},
+		errors:    map[metricsKey]uint64{},
+		retries:   map[metricsKey]uint64{},
+		latencies: map[metricsKey]*histogram{},
+	}
+}
+
+// ObserveRequest implements MetricsRecorder
+func (m *MetricsCollector) ObserveRequest(endpoint, method string, status int, latency time.Duration) {
+	code := strconv.Itoa(status)
+	if status == 0 {
+		code = "transport_error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[metricsKey{endpoint, method, code}]++
+	if status < 200 || status >= 400 {
+		m.errors[metricsKey{endpoint, method, code}]++
+	}
+
+	key := metricsKey{endpoint: endpoint, method: method}
+	h, ok := m.latencies[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[key] = h
+	}
+	sec := latency.Seconds()
+	for i, b := range m.buckets {
+		if sec <= b {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += sec
+}
+
+// IncRetry implements MetricsRecorder
+func (m *MetricsCollector) IncRetry(endpoint, method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retries[metricsKey{endpoint: endpoint, method: method}]++
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *MetricsCollector) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ew := &errWriter{w: w}
+
+	ew.printf("# HELP digdag_client_requests_total Total number of requests to digdag-server.\n")
+	ew.printf("# TYPE digdag_client_requests_total counter\n")
+	for _, k := range sortedKeys(m.requests) {
+		ew.printf("digdag_client_requests_total{%s} %d\n", k.labels(true), m.requests[k])
+	}
+
+	ew.printf("# HELP digdag_client_errors_total Total number of failed requests to digdag-server.\n")
+	ew.printf("# TYPE digdag_client_errors_total counter\n")
+	for _, k := range sortedKeys(m.errors) {
+		ew.printf("digdag_client_errors_total{%s} %d\n", k.labels(true), m.errors[k])
+	}
+
+	ew.printf("# HELP digdag_client_retries_total Total number of retried requests to digdag-server.\n")
+	ew.printf("# TYPE digdag_client_retries_total counter\n")
+	for _, k := range sortedKeys(m.retries) {
+		ew.printf("digdag_client_retries_total{%s} %d\n", k.labels(false), m.retries[k])
+	}
+
+	ew.printf("# HELP digdag_client_request_duration_seconds Latency of requests to digdag-server.\n")
+	ew.printf("# TYPE digdag_client_request_duration_seconds histogram\n")
+	keys := make([]metricsKey, 0, len(m.latencies))
+	for k := range m.latencies {
+		keys = append(keys, k)
+	}
+	sortMetricsKeys(keys)
+	for _, k := range keys {
+		h := m.latencies[k]
+		labels := k.labels(false)
+		for i, b := range m.buckets {
+			ew.printf("digdag_client_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(b, 'g', -1, 64), h.counts[i])
+		}
+		ew.printf("digdag_client_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		ew.printf("digdag_client_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		ew.printf("digdag_client_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	return ew.err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format
+func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WritePrometheus(w)
+}
+
+func (k metricsKey) labels(withCode bool) string {
+	l := fmt.Sprintf("endpoint=%q,method=%q", k.endpoint, k.method)
+	if withCode {
+		l += fmt.Sprintf(",code=%q", k.code)
+	}
+	return l
+}
+
+func sortedKeys(m map[metricsKey]uint64) []metricsKey {
+	keys := make([]metricsKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortMetricsKeys(keys)
+	return keys
+}
+
+func sortMetricsKeys(keys []metricsKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+}
+
+// errWriter keeps the first error of successive writes
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, a ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, a...)
+}
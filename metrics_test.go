@@ -0,0 +1,115 @@
+package digdag
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_endpointPattern(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		// Test cases
+		{path: "/api/projects", want: "/api/projects"},
+		{path: "/api/projects/1/workflows", want: "/api/projects/{id}/workflows"},
+		{path: "/api/attempts/27/tasks", want: "/api/attempts/{id}/tasks"},
+		{path: "/api/logs/11/files/+test+test@5a54eea130ef7740.73100@test.local.log.gz", want: "/api/logs/{id}/files/{name}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := endpointPattern(tt.path); got != tt.want {
+				t.Errorf("endpointPattern() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Metrics(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/projects/999/workflows" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, `{"attempts":[{"id":"27"}]}`)
+	}))
+	defer ts.Close()
+
+	m := NewMetricsCollector(60)
+	c := newTestClient(ts.URL)
+	c.Metrics = m
+	c.RetryPolicy = newTestRetryPolicy()
+
+	if _, err := c.GetAttempts(nil, true); err != nil {
+		t.Fatalf("Client.GetAttempts() error = %v", err)
+	}
+	if _, err := c.GetWorkflow("999", "test"); !IsNotFound(err) {
+		t.Fatalf("Client.GetWorkflow() error = %v, want not found", err)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatalf("MetricsCollector.WritePrometheus() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		`digdag_client_requests_total{endpoint="/api/attempts",method="GET",code="200"} 1`,
+		`digdag_client_requests_total{endpoint="/api/attempts",method="GET",code="503"} 1`,
+		`digdag_client_requests_total{endpoint="/api/projects/{id}/workflows",method="GET",code="404"} 1`,
+		`digdag_client_errors_total{endpoint="/api/attempts",method="GET",code="503"} 1`,
+		`digdag_client_errors_total{endpoint="/api/projects/{id}/workflows",method="GET",code="404"} 1`,
+		`digdag_client_retries_total{endpoint="/api/attempts",method="GET"} 1`,
+		`digdag_client_request_duration_seconds_bucket{endpoint="/api/attempts",method="GET",le="60"} 2`,
+		`digdag_client_request_duration_seconds_bucket{endpoint="/api/attempts",method="GET",le="+Inf"} 2`,
+		`digdag_client_request_duration_seconds_count{endpoint="/api/attempts",method="GET"} 2`,
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("WritePrometheus() = %v, want to contain %v", got, want)
+		}
+	}
+	if strings.Contains(got, `digdag_client_errors_total{endpoint="/api/attempts",method="GET",code="200"}`) {
+		t.Errorf("WritePrometheus() = %v, successful requests should not be counted as errors", got)
+	}
+}
+
+func TestMetricsCollector_ServeHTTP(t *testing.T) {
+	m := NewMetricsCollector(0.1, 1)
+	m.ObserveRequest("/api/projects", http.MethodGet, 0, 500*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := `# HELP digdag_client_requests_total Total number of requests to digdag-server.
+# TYPE digdag_client_requests_total counter
+digdag_client_requests_total{endpoint="/api/projects",method="GET",code="transport_error"} 1
+# HELP digdag_client_errors_total Total number of failed requests to digdag-server.
+# TYPE digdag_client_errors_total counter
+digdag_client_errors_total{endpoint="/api/projects",method="GET",code="transport_error"} 1
+# HELP digdag_client_retries_total Total number of retried requests to digdag-server.
+# TYPE digdag_client_retries_total counter
+# HELP digdag_client_request_duration_seconds Latency of requests to digdag-server.
+# TYPE digdag_client_request_duration_seconds histogram
+digdag_client_request_duration_seconds_bucket{endpoint="/api/projects",method="GET",le="0.1"} 0
+digdag_client_request_duration_seconds_bucket{endpoint="/api/projects",method="GET",le="1"} 1
+digdag_client_request_duration_seconds_bucket{endpoint="/api/projects",method="GET",le="+Inf"} 1
+digdag_client_request_duration_seconds_sum{endpoint="/api/projects",method="GET"} 0.5
+digdag_client_request_duration_seconds_count{endpoint="/api/projects",method="GET"} 1
+`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("MetricsCollector.ServeHTTP() = %v, want %v", got, want)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("Content-Type = %v, want text/plain", got)
+	}
+}
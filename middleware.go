@@ -0,0 +1,68 @@
+package digdag
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the http.RoundTripper used for every request to digdag-server
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to use a function as http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use appends middlewares to the chain. The first middleware is the outermost one.
+func (c *Client) Use(mws ...Middleware) {
+	c.Middlewares = append(c.Middlewares, mws...)
+}
+
+// RequestMutator returns the middleware calling f with a copy of each request before it is sent
+func RequestMutator(f func(req *http.Request) error) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// RoundTripper must not modify the given request
+			req = req.Clone(req.Context())
+			if err := f(req); err != nil {
+				if req.Body != nil {
+					req.Body.Close()
+				}
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// ResponseObserver returns the middleware calling f with each request and its result
+func ResponseObserver(f func(req *http.Request, resp *http.Response, err error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			f(req, resp, err)
+			return resp, err
+		})
+	}
+}
+
+// httpClient returns the http.Client with the middleware chain applied to its transport
+func (c *Client) httpClient() *http.Client {
+	if len(c.Middlewares) == 0 {
+		return c.HTTPClient
+	}
+
+	rt := c.HTTPClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		rt = c.Middlewares[i](rt)
+	}
+
+	hc := *c.HTTPClient
+	hc.Transport = rt
+	return &hc
+}
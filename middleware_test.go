@@ -0,0 +1,117 @@
+package digdag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Middlewares(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+":before")
+				resp, err := next.RoundTrip(req)
+				order = append(order, name+":after")
+				return resp, err
+			})
+		}
+	}
+
+	var observed []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Request-Id"); got != "req-1" {
+			t.Errorf("X-Request-Id = %v, want %v", got, "req-1")
+		}
+		http.ServeFile(w, r, "testdata/+test+test@5a54eea130ef7740.73100@test.local.log.gz")
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	c.Use(
+		trace("first"),
+		trace("second"),
+		RequestMutator(func(req *http.Request) error {
+			req.Header.Set("X-Request-Id", "req-1")
+			return nil
+		}),
+		ResponseObserver(func(req *http.Request, resp *http.Response, err error) {
+			observed = append(observed, resp.StatusCode)
+		}),
+	)
+
+	// Middlewares are applied to log downloads too
+	if _, err := c.GetLogText("11", "+test+test@5a54eea130ef7740.73100@test.local.log.gz"); err != nil {
+		t.Fatalf("Client.GetLogText() error = %v", err)
+	}
+
+	wantOrder := []string{"first:before", "second:before", "second:after", "first:after"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("order = %v, want %v", order, wantOrder)
+	}
+	if !reflect.DeepEqual(observed, []int{http.StatusOK}) {
+		t.Errorf("observed = %v, want %v", observed, []int{http.StatusOK})
+	}
+	if c.HTTPClient.Transport != nil {
+		t.Errorf("HTTPClient.Transport should not be modified but %v", c.HTTPClient.Transport)
+	}
+}
+
+func TestRequestMutator_Error(t *testing.T) {
+	wantErr := errors.New("mutator error")
+	c := newTestClient("http://localhost:65432")
+	c.Use(RequestMutator(func(req *http.Request) error {
+		return wantErr
+	}))
+	if _, err := c.GetProjects(); !errors.Is(err, wantErr) {
+		t.Errorf("Client.GetProjects() error = %v, want %v", err, wantErr)
+	}
+}
+
+type requestIDKey struct{}
+
+// Propagate a request ID from the context to digdag-server
+func ExampleRequestMutator() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("X-Request-Id:", r.Header.Get("X-Request-Id"))
+		fmt.Fprintln(w, `{"projects":[]}`)
+	}))
+	defer ts.Close()
+
+	client, _ := NewClient(ts.URL, WithMiddleware(
+		RequestMutator(func(req *http.Request) error {
+			if id, ok := req.Context().Value(requestIDKey{}).(string); ok {
+				req.Header.Set("X-Request-Id", id)
+			}
+			return nil
+		}),
+	))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "5a54eea1")
+	client.GetProjectsContext(ctx)
+	// Output: X-Request-Id: 5a54eea1
+}
+
+// Record the status code of every response
+func ExampleResponseObserver() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"projects":[]}`)
+	}))
+	defer ts.Close()
+
+	client, _ := NewClient(ts.URL, WithMiddleware(
+		ResponseObserver(func(req *http.Request, resp *http.Response, err error) {
+			if err == nil {
+				fmt.Println(req.Method, req.URL.Path, resp.StatusCode)
+			}
+		}),
+	))
+
+	client.GetProjects()
+	// Output: GET /api/projects 200
+}
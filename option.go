@@ -0,0 +1,139 @@
+package digdag
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Option is the functional option for NewClient
+type Option func(*Client) error
+
+// WithHTTPClient sets the http.Client used for requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		if hc == nil {
+			return errors.New("http client is nil")
+		}
+		c.HTTPClient = hc
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each request.
+// It is applied after all other options, so it also applies to a client given by WithHTTPClient in any order.
+// The http.Client is copied so that a client given by WithHTTPClient is not modified.
+func WithTimeout(d time.Duration) Option {
+	return deferHTTPClientOption(func(c *Client) error {
+		hc := *c.HTTPClient
+		hc.Timeout = d
+		c.HTTPClient = &hc
+		return nil
+	})
+}
+
+// WithTLSConfig sets the TLS configuration of the transport.
+// It is applied after all other options, so it also applies to a client given by WithHTTPClient in any order.
+// The http.Client and its transport are copied so that a client given by WithHTTPClient is not modified.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return deferHTTPClientOption(func(c *Client) error {
+		var tr *http.Transport
+		switch t := c.HTTPClient.Transport.(type) {
+		case nil:
+			tr = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			tr = t.Clone()
+		default:
+			return errors.New("TLS config can only be set on *http.Transport")
+		}
+		tr.TLSClientConfig = cfg
+
+		hc := *c.HTTPClient
+		hc.Transport = tr
+		c.HTTPClient = &hc
+		return nil
+	})
+}
+
+// deferHTTPClientOption returns an option which modifies the http.Client after all options are applied
+func deferHTTPClientOption(opt Option) Option {
+	return func(c *Client) error {
+		c.httpClientOptions = append(c.httpClientOptions, opt)
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header
+func WithUserAgent(ua string) Option {
+	return func(c *Client) error {
+		c.UserAgent = ua
+		return nil
+	}
+}
+
+// WithHeader adds a custom header sent with every request
+func WithHeader(key, value string) Option {
+	return func(c *Client) error {
+		c.CustomHeaders.Set(key, value)
+		return nil
+	}
+}
+
+// WithVerbose enables logging of request and response headers and bodies
+func WithVerbose(verbose bool) Option {
+	return func(c *Client) error {
+		c.Verbose = verbose
+		return nil
+	}
+}
+
+// WithLogger sets the structured logger for requests
+func WithLogger(l Logger) Option {
+	return func(c *Client) error {
+		c.Logger = l
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the retry policy. Pass NewRetryPolicy() to use default values.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(c *Client) error {
+		c.RetryPolicy = p
+		return nil
+	}
+}
+
+// WithCredentials sets the credentials provider
+func WithCredentials(p CredentialsProvider) Option {
+	return func(c *Client) error {
+		c.Credentials = p
+		return nil
+	}
+}
+
+// WithBasicAuth sets the credentials for HTTP basic authentication
+func WithBasicAuth(username, password string) Option {
+	return WithCredentials(&BasicAuth{Username: username, Password: password})
+}
+
+// WithBearerToken sets a static bearer token
+func WithBearerToken(token string) Option {
+	return WithCredentials(BearerToken(token))
+}
+
+// WithMiddleware appends middlewares to the chain applied to every request
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *Client) error {
+		c.Use(mws...)
+		return nil
+	}
+}
+
+// WithMetrics sets the recorder of request metrics, e.g. NewMetricsCollector()
+func WithMetrics(m MetricsRecorder) Option {
+	return func(c *Client) error {
+		c.Metrics = m
+		return nil
+	}
+}
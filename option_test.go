@@ -0,0 +1,145 @@
+package digdag
+
+import (
+	"crypto/tls"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	hc := &http.Client{}
+	logger := &testLogger{}
+	policy := NewRetryPolicy()
+
+	c, err := NewClient("http://digdag.local:65432",
+		WithHTTPClient(hc),
+		WithTimeout(10*time.Second),
+		WithUserAgent("test-agent"),
+		WithHeader("X-Custom-Header", "hoge"),
+		WithVerbose(true),
+		WithLogger(logger),
+		WithRetryPolicy(policy),
+		WithBearerToken("token"),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if got := c.BaseURL.String(); got != "http://digdag.local:65432" {
+		t.Errorf("BaseURL = %v, want %v", got, "http://digdag.local:65432")
+	}
+	if c.HTTPClient.Timeout != 10*time.Second {
+		t.Errorf("HTTPClient.Timeout = %v, want %v", c.HTTPClient.Timeout, 10*time.Second)
+	}
+	if hc.Timeout != 0 {
+		t.Errorf("given http.Client should not be modified but Timeout = %v", hc.Timeout)
+	}
+	if c.UserAgent != "test-agent" {
+		t.Errorf("UserAgent = %v, want %v", c.UserAgent, "test-agent")
+	}
+	if got := c.CustomHeaders.Get("X-Custom-Header"); got != "hoge" {
+		t.Errorf("CustomHeaders = %v, want %v", got, "hoge")
+	}
+	if !c.Verbose {
+		t.Errorf("Verbose = %v, want %v", c.Verbose, true)
+	}
+	if c.Logger != logger {
+		t.Errorf("Logger = %v, want %v", c.Logger, logger)
+	}
+	if c.RetryPolicy != policy {
+		t.Errorf("RetryPolicy = %v, want %v", c.RetryPolicy, policy)
+	}
+	if !reflect.DeepEqual(c.Credentials, BearerToken("token")) {
+		t.Errorf("Credentials = %v, want %v", c.Credentials, BearerToken("token"))
+	}
+}
+
+func TestNewClient_WithTimeoutBeforeHTTPClient(t *testing.T) {
+	hc := &http.Client{}
+	c, err := NewClient("", WithTimeout(10*time.Second), WithHTTPClient(hc))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if c.HTTPClient.Timeout != 10*time.Second {
+		t.Errorf("HTTPClient.Timeout = %v, want %v", c.HTTPClient.Timeout, 10*time.Second)
+	}
+	if hc.Timeout != 0 {
+		t.Errorf("given http.Client should not be modified but Timeout = %v", hc.Timeout)
+	}
+}
+
+func TestNewClient_WithTLSConfig(t *testing.T) {
+	cfg := &tls.Config{InsecureSkipVerify: true}
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		// Test cases
+		{
+			name: "test default transport",
+			opts: []Option{WithTLSConfig(cfg)},
+		},
+		{
+			name: "test given transport",
+			opts: []Option{WithHTTPClient(&http.Client{Transport: &http.Transport{}}), WithTLSConfig(cfg)},
+		},
+		{
+			name: "test http client given after",
+			opts: []Option{WithTLSConfig(cfg), WithHTTPClient(&http.Client{Transport: &http.Transport{}})},
+		},
+		{
+			name:    "test unsupported transport",
+			opts:    []Option{WithHTTPClient(&http.Client{Transport: http.NewFileTransport(http.Dir("."))}), WithTLSConfig(cfg)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient("", tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			tr, ok := c.HTTPClient.Transport.(*http.Transport)
+			if !ok {
+				t.Fatalf("Transport = %T, want *http.Transport", c.HTTPClient.Transport)
+			}
+			if tr.TLSClientConfig != cfg {
+				t.Errorf("TLSClientConfig = %v, want %v", tr.TLSClientConfig, cfg)
+			}
+			if http.DefaultTransport.(*http.Transport).TLSClientConfig == cfg {
+				t.Error("http.DefaultTransport should not be modified")
+			}
+		})
+	}
+}
+
+func TestNewClient_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		opts    []Option
+	}{
+		// Test cases
+		{
+			name:    "test invalid url",
+			baseURL: "http://[::1",
+		},
+		{
+			name: "test nil http client",
+			opts: []Option{WithHTTPClient(nil)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewClient(tt.baseURL, tt.opts...); err == nil {
+				t.Error("NewClient() error should not be nil")
+			}
+		})
+	}
+}
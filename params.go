@@ -0,0 +1,194 @@
+package digdag
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Params is the params of an attempt.
+// Numbers are decoded as json.Number so that large integers are not rounded.
+type Params map[string]interface{}
+
+// UnmarshalJSON decodes params keeping numbers as json.Number
+func (p *Params) UnmarshalJSON(b []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var m map[string]interface{}
+	if err := decoder.Decode(&m); err != nil {
+		return err
+	}
+	*p = m
+	return nil
+}
+
+// ParseParams to parse params given as `key=value` or `key:=json`.
+// A value after `=` is a string as is, and a value after `:=` is decoded as JSON with numbers as json.Number.
+func ParseParams(args []string) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("param `%s` is invalid, expected key=value or key:=json", arg)
+		}
+
+		key, val := arg[:i], arg[i+1:]
+		if !strings.HasSuffix(key, ":") {
+			params[key] = val
+			continue
+		}
+
+		key = strings.TrimSuffix(key, ":")
+		if key == "" {
+			return nil, fmt.Errorf("param `%s` is invalid, expected key=value or key:=json", arg)
+		}
+		v, err := decodeJSONValue([]byte(val))
+		if err != nil {
+			return nil, fmt.Errorf("param `%s` is invalid: %v", key, err)
+		}
+		params[key] = v
+	}
+	return params, nil
+}
+
+// LoadParamsFile to load params from a JSON or YAML file like `digdag -P params.yml`.
+// Numbers are json.Number as with ParseParams.
+func LoadParamsFile(path string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := parseParamsFile(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return params, nil
+}
+
+func parseParamsFile(b []byte) (map[string]interface{}, error) {
+	var v interface{}
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		var err error
+		v, err = decodeJSONValue(trimmed)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var y yamlValue
+		if err := yaml.Unmarshal(b, &y); err != nil {
+			return nil, err
+		}
+		if y.v == nil {
+			return map[string]interface{}{}, nil
+		}
+		v = y.v
+	}
+
+	params, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("params must be a mapping")
+	}
+	return params, nil
+}
+
+// yamlValue decodes YAML into the types decoded from JSON, reading scalars as digdag does:
+// numbers keep their text as json.Number, timestamps are strings and YAML 1.1 bools such as `yes` are bools.
+type yamlValue struct {
+	v interface{}
+}
+
+func (y *yamlValue) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var m map[string]yamlValue
+		if err := node.Decode(&m); err != nil {
+			return err
+		}
+		v := make(map[string]interface{}, len(m))
+		for k, e := range m {
+			v[k] = e.v
+		}
+		y.v = v
+		return nil
+	case yaml.SequenceNode:
+		// Decode items one by one since decoding into []yamlValue drops nulls
+		v := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			var e yamlValue
+			if err := item.Decode(&e); err != nil {
+				return err
+			}
+			v[i] = e.v
+		}
+		y.v = v
+		return nil
+	}
+
+	switch node.ShortTag() {
+	case "!!timestamp":
+		y.v = node.Value
+		return nil
+	case "!!int", "!!float":
+		if v, err := decodeJSONValue([]byte(node.Value)); err == nil {
+			if _, ok := v.(json.Number); ok {
+				y.v = v
+				return nil
+			}
+		}
+	case "!!str":
+		if node.Style == 0 {
+			switch node.Value {
+			case "yes", "Yes", "YES", "on", "On", "ON":
+				y.v = true
+				return nil
+			case "no", "No", "NO", "off", "Off", "OFF":
+				y.v = false
+				return nil
+			}
+		}
+	}
+
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	// numbers not in JSON syntax such as 0x1f or .5
+	switch n := v.(type) {
+	case int:
+		v = json.Number(strconv.Itoa(n))
+	case uint64:
+		v = json.Number(strconv.FormatUint(n, 10))
+	case float64:
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			return fmt.Errorf("line %d: %s is not supported in params", node.Line, node.Value)
+		}
+		v = json.Number(strconv.FormatFloat(n, 'g', -1, 64))
+	}
+	y.v = v
+	return nil
+}
+
+// decodeJSONValue decodes b like json.Unmarshal into interface{}, except that numbers are json.Number
+func decodeJSONValue(b []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+	return v, nil
+}
@@ -0,0 +1,330 @@
+package digdag
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		// Test cases
+		{
+			name: "test string params",
+			args: []string{"key=value", "query=a=b", "empty="},
+			want: map[string]interface{}{"key": "value", "query": "a=b", "empty": ""},
+		},
+		{
+			name: "test json params",
+			args: []string{"n:=1", "b:=true", "s:=\"a\"", "null:=null", "list:=[1,\"a\"]", "obj:={\"a\":{\"b\":1}}"},
+			want: map[string]interface{}{
+				"n":    json.Number("1"),
+				"b":    true,
+				"s":    "a",
+				"null": nil,
+				"list": []interface{}{json.Number("1"), "a"},
+				"obj":  map[string]interface{}{"a": map[string]interface{}{"b": json.Number("1")}},
+			},
+		},
+		{
+			name: "test large integer",
+			args: []string{"id:=12345678901234567891"},
+			want: map[string]interface{}{"id": json.Number("12345678901234567891")},
+		},
+		{
+			name: "test empty",
+			args: nil,
+			want: map[string]interface{}{},
+		},
+		{
+			name:    "test no value",
+			args:    []string{"key"},
+			wantErr: true,
+		},
+		{
+			name:    "test no key",
+			args:    []string{"=value"},
+			wantErr: true,
+		},
+		{
+			name:    "test no key with json",
+			args:    []string{":=1"},
+			wantErr: true,
+		},
+		{
+			name:    "test invalid json",
+			args:    []string{"key:=value"},
+			wantErr: true,
+		},
+		{
+			name:    "test trailing data",
+			args:    []string{"key:=1 2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseParams(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseParams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadParamsFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		// Test cases
+		{
+			name: "test yaml",
+			path: "testdata/params.yml",
+			want: map[string]interface{}{
+				"target":  "users",
+				"date":    "2017-06-24",
+				"limit":   json.Number("100"),
+				"ratio":   json.Number("0.5"),
+				"dry_run": false,
+				"empty":   nil,
+				"url":     "http://example.com/a#b",
+				"query":   "it's a=b",
+				"tables":  []interface{}{"users", "orders"},
+				"db": map[string]interface{}{
+					"host":    "localhost",
+					"port":    json.Number("5432"),
+					"options": map[string]interface{}{"ssl": true},
+				},
+				"jobs": []interface{}{
+					map[string]interface{}{"name": "a", "retry": json.Number("3")},
+					map[string]interface{}{"name": "b", "tags": []interface{}{"x", "y"}},
+				},
+			},
+		},
+		{
+			name: "test json",
+			path: "testdata/params.json",
+			want: map[string]interface{}{
+				"target": "users",
+				"limit":  json.Number("100"),
+				"db":     map[string]interface{}{"host": "localhost", "port": json.Number("5432")},
+			},
+		},
+		{
+			name:    "test file not found",
+			path:    "testdata/not_found.yml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadParamsFile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadParamsFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadParamsFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseParamsFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		// Test cases
+		{
+			name: "test empty",
+			in:   "# nothing\n",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "test nested sequences",
+			in:   "a:\n  - - 1\n    - 2\n  -\n    b: c\n  -\n",
+			want: map[string]interface{}{
+				"a": []interface{}{
+					[]interface{}{json.Number("1"), json.Number("2")},
+					map[string]interface{}{"b": "c"},
+					nil,
+				},
+			},
+		},
+		{
+			name: "test quoted keys and values",
+			in:   "\"a: b\": 'c # d'\n'e': \"f\\n\"\n",
+			want: map[string]interface{}{"a: b": "c # d", "e": "f\n"},
+		},
+		{
+			name:    "test not a mapping",
+			in:      "- a\n- b\n",
+			wantErr: true,
+		},
+		{
+			name:    "test bad indentation",
+			in:      "a: 1\n  b: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "test duplicated key",
+			in:      "a: 1\na: 2\n",
+			wantErr: true,
+		},
+		{
+			name: "test block scalars",
+			in:   "a: |\n  line1\n  line2\nb: >-\n  folded\n  text\n",
+			want: map[string]interface{}{"a": "line1\nline2\n", "b": "folded text"},
+		},
+		{
+			name: "test flow values",
+			in:   "tags: [x, y]\ndb: {host: localhost, port: 5432}\n",
+			want: map[string]interface{}{
+				"tags": []interface{}{"x", "y"},
+				"db":   map[string]interface{}{"host": "localhost", "port": json.Number("5432")},
+			},
+		},
+		{
+			name: "test yaml 1.1 bools",
+			in:   "a: yes\nb: off\nc: 'yes'\n",
+			want: map[string]interface{}{"a": true, "b": false, "c": "yes"},
+		},
+		{
+			name: "test anchor and alias",
+			in:   "a: &x {b: 1}\nc: *x\n",
+			want: map[string]interface{}{
+				"a": map[string]interface{}{"b": json.Number("1")},
+				"c": map[string]interface{}{"b": json.Number("1")},
+			},
+		},
+		{
+			name: "test numbers",
+			in:   "id: 12345678901234567891\nneg: -9223372036854775808\nratio: 1.5e-3\n",
+			want: map[string]interface{}{
+				"id":    json.Number("12345678901234567891"),
+				"neg":   json.Number("-9223372036854775808"),
+				"ratio": json.Number("1.5e-3"),
+			},
+		},
+		{
+			name: "test non-string keys",
+			in:   "1: a\ntrue: b\n",
+			want: map[string]interface{}{"1": "a", "true": "b"},
+		},
+		{
+			name: "test scalars as written",
+			in:   "d: 2017-01-01\nt: 2017-01-01T01:02:03Z\nf: 1.0\ne: 1e3\nhex: 0x1f\nhalf: .5\ns: !!str 1\n",
+			want: map[string]interface{}{
+				"d":    "2017-01-01",
+				"t":    "2017-01-01T01:02:03Z",
+				"f":    json.Number("1.0"),
+				"e":    json.Number("1e3"),
+				"hex":  json.Number("31"),
+				"half": json.Number("0.5"),
+				"s":    "1",
+			},
+		},
+		{
+			name: "test merge key",
+			in:   "base: &b {a: 1, b: 2}\nx:\n  <<: *b\n  b: 3\n",
+			want: map[string]interface{}{
+				"base": map[string]interface{}{"a": json.Number("1"), "b": json.Number("2")},
+				"x":    map[string]interface{}{"a": json.Number("1"), "b": json.Number("3")},
+			},
+		},
+		{
+			name:    "test colon in plain scalar",
+			in:      "msg: a: b\n",
+			wantErr: true,
+		},
+		{
+			name:    "test infinity",
+			in:      "a: .inf\n",
+			wantErr: true,
+		},
+		{
+			name:    "test missing colon",
+			in:      "a\n",
+			wantErr: true,
+		},
+		{
+			name:    "test tab indentation",
+			in:      "a:\n\tb: 1\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseParamsFile([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseParamsFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseParamsFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAttempt_ParamsJSONRoundTrip(t *testing.T) {
+	fileParams, err := LoadParamsFile("testdata/params.yml")
+	if err != nil {
+		t.Fatalf("LoadParamsFile() error = %v", err)
+	}
+	argParams, err := ParseParams([]string{"id:=12345678901234567891", "pi:=3.141592653589793238", "obj:={\"big\":[9007199254740993]}"})
+	if err != nil {
+		t.Fatalf("ParseParams() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		// Test cases
+		{
+			name:   "test params file",
+			params: fileParams,
+		},
+		{
+			name:   "test large numbers",
+			params: argParams,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca := &CreateAttempt{WorkflowID: "2", Params: tt.params}
+			b, err := json.Marshal(ca)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+
+			// Decode as a response of digdag-server
+			resp := &http.Response{Body: ioutil.NopCloser(bytes.NewReader(b))}
+			var got *Attempt
+			if err := decodeBody(resp, &got); err != nil {
+				t.Fatalf("decodeBody() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Params, ca.Params) {
+				t.Errorf("round trip params = %v, want %v", got.Params, ca.Params)
+			}
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package digdag
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
 )
 
 // projectsWrapper is struct for received json
@@ -12,16 +17,46 @@ type projectsWrapper struct {
 
 // Project is struct for digdag project
 type Project struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Revision    string     `json:"revision,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+	ArchiveType string     `json:"archiveType,omitempty"`
+	ArchiveMd5  string     `json:"archiveMd5,omitempty"`
+}
+
+// ProjectRef is struct for the project referenced by other resources such as sessions and workflows
+type ProjectRef struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
 
+type revisionsWrapper struct {
+	Revisions []*Revision `json:"revisions"`
+}
+
+// Revision is struct for digdag project revision
+type Revision struct {
+	Revision    string                 `json:"revision"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	ArchiveType string                 `json:"archiveType"`
+	ArchiveMd5  string                 `json:"archiveMd5"`
+	UserInfo    map[string]interface{} `json:"userInfo"`
+}
+
 // GetProjects to get projects
 func (c *Client) GetProjects() ([]*Project, error) {
+	return c.GetProjectsContext(context.Background())
+}
+
+// GetProjectsContext to get projects with the given context
+func (c *Client) GetProjectsContext(ctx context.Context) ([]*Project, error) {
 	spath := "/api/projects"
 
 	var pw *projectsWrapper
-	resp, err := c.NewRequest(http.MethodGet, spath, nil)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +70,11 @@ func (c *Client) GetProjects() ([]*Project, error) {
 
 // GetProject to get project by project name
 func (c *Client) GetProject(name string) (*Project, error) {
+	return c.GetProjectContext(context.Background(), name)
+}
+
+// GetProjectContext to get project by project name with the given context
+func (c *Client) GetProjectContext(ctx context.Context, name string) (*Project, error) {
 	spath := "/api/projects"
 
 	var pw *projectsWrapper
@@ -44,7 +84,7 @@ func (c *Client) GetProject(name string) (*Project, error) {
 		},
 	}
 
-	resp, err := c.NewRequest(http.MethodGet, spath, ro)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, ro)
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +95,177 @@ func (c *Client) GetProject(name string) (*Project, error) {
 
 	// if an empty array (= project not found)
 	if len(pw.Projects) == 0 {
-		return nil, fmt.Errorf("project `%s` not found", name)
+		return nil, &NotFoundError{Name: name, Err: ErrProjectNotFound}
 	}
 
 	return pw.Projects[0], nil
 }
+
+// GetProjectByID to get project by project ID
+func (c *Client) GetProjectByID(projectID string) (*Project, error) {
+	return c.GetProjectByIDContext(context.Background(), projectID)
+}
+
+// GetProjectByIDContext to get project by project ID with the given context
+func (c *Client) GetProjectByIDContext(ctx context.Context, projectID string) (*Project, error) {
+	spath := fmt.Sprintf("/api/projects/%s", projectID)
+
+	var project *Project
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, notFound(err, "id="+projectID, ErrProjectNotFound)
+	}
+
+	if err := decodeBody(resp, &project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// DeleteProject to delete project by project ID and return the deleted project
+func (c *Client) DeleteProject(projectID string) (*Project, error) {
+	return c.DeleteProjectContext(context.Background(), projectID)
+}
+
+// DeleteProjectContext to delete project by project ID with the given context
+func (c *Client) DeleteProjectContext(ctx context.Context, projectID string) (*Project, error) {
+	spath := fmt.Sprintf("/api/projects/%s", projectID)
+
+	var project *Project
+	resp, err := c.NewRequestContext(ctx, http.MethodDelete, spath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// GetProjectRevisions to get revisions of the project
+func (c *Client) GetProjectRevisions(projectID string) ([]*Revision, error) {
+	return c.GetProjectRevisionsContext(context.Background(), projectID)
+}
+
+// GetProjectRevisionsContext to get revisions of the project with the given context
+func (c *Client) GetProjectRevisionsContext(ctx context.Context, projectID string) ([]*Revision, error) {
+	spath := fmt.Sprintf("/api/projects/%s/revisions", projectID)
+
+	var rw *revisionsWrapper
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &rw); err != nil {
+		return nil, err
+	}
+
+	return rw.Revisions, nil
+}
+
+// PushProject to upload the project archive of dir like `digdag push`.
+// If revision is empty, a random UUID is used.
+func (c *Client) PushProject(name, revision, dir string) (*Project, error) {
+	return c.PushProjectContext(context.Background(), name, revision, dir)
+}
+
+// PushProjectContext to upload the project archive of dir with the given context
+func (c *Client) PushProjectContext(ctx context.Context, name, revision, dir string) (*Project, error) {
+	spath := "/api/projects"
+
+	if revision == "" {
+		revision = uuid.NewV4().String()
+	}
+
+	archive, err := createProjectArchive(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ro := &RequestOpts{
+		Params: map[string]string{
+			"project":  name,
+			"revision": revision,
+		},
+		Headers: map[string]string{
+			"Content-Type": "application/gzip",
+		},
+		Body: archive,
+	}
+
+	var project *Project
+	resp, err := c.NewRequestContext(ctx, http.MethodPut, spath, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// DownloadProjectArchive to download the tar.gz archive of the project.
+// If revision is empty, the latest revision is downloaded. The caller must close the returned reader.
+func (c *Client) DownloadProjectArchive(projectID, revision string) (io.ReadCloser, error) {
+	return c.DownloadProjectArchiveContext(context.Background(), projectID, revision)
+}
+
+// DownloadProjectArchiveContext to download the tar.gz archive of the project with the given context
+func (c *Client) DownloadProjectArchiveContext(ctx context.Context, projectID, revision string) (io.ReadCloser, error) {
+	spath := fmt.Sprintf("/api/projects/%s/archive", projectID)
+
+	ro := &RequestOpts{
+		Params: map[string]string{},
+	}
+	if revision != "" {
+		ro.Params["revision"] = revision
+	}
+
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// DiffProjectRevisions to compare the files of two revisions of the project
+func (c *Client) DiffProjectRevisions(projectID, fromRevision, toRevision string) (*ProjectDiff, error) {
+	return c.DiffProjectRevisionsContext(context.Background(), projectID, fromRevision, toRevision)
+}
+
+// DiffProjectRevisionsContext to compare the files of two revisions of the project with the given context
+func (c *Client) DiffProjectRevisionsContext(ctx context.Context, projectID, fromRevision, toRevision string) (*ProjectDiff, error) {
+	from, err := c.getProjectFiles(ctx, projectID, fromRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := c.getProjectFiles(ctx, projectID, toRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProjectDiff{
+		ProjectID:    projectID,
+		FromRevision: fromRevision,
+		ToRevision:   toRevision,
+		Files:        diffFiles(from, to),
+	}, nil
+}
+
+func (c *Client) getProjectFiles(ctx context.Context, projectID, revision string) (map[string][]byte, error) {
+	rc, err := c.DownloadProjectArchiveContext(ctx, projectID, revision)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return readProjectArchive(rc)
+}
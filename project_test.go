@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestClient_GetProjects(t *testing.T) {
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-13T03:11:12Z")
+	updatedAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-13T03:11:12Z")
 	tests := []struct {
 		name    string
 		res     string
@@ -35,8 +40,14 @@ func TestClient_GetProjects(t *testing.T) {
 			`,
 			want: []*Project{
 				{
-					ID:   "1",
-					Name: "test",
+					ID:          "1",
+					Name:        "test",
+					Revision:    "59b4f254-dc4f-429d-b0d6-18a676bb9e5f",
+					CreatedAt:   createdAt,
+					UpdatedAt:   updatedAt,
+					DeletedAt:   nil,
+					ArchiveType: "db",
+					ArchiveMd5:  "PeWLToy+/ygCFXQdXlgUbQ==",
 				},
 			},
 		},
@@ -56,7 +67,7 @@ func TestClient_GetProjects(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			c, err := NewClient(ts.URL, false)
+			c, err := NewClient(ts.URL)
 			if err != nil {
 				t.Error("err should be nil but: ", err)
 			}
@@ -74,6 +85,8 @@ func TestClient_GetProjects(t *testing.T) {
 }
 
 func TestClient_GetProject(t *testing.T) {
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-13T03:11:12Z")
+	updatedAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-13T03:11:12Z")
 	type args struct {
 		name string
 	}
@@ -104,8 +117,14 @@ func TestClient_GetProject(t *testing.T) {
 			}
 			`,
 			want: &Project{
-				ID:   "1",
-				Name: "test",
+				ID:          "1",
+				Name:        "test",
+				Revision:    "59b4f254-dc4f-429d-b0d6-18a676bb9e5f",
+				CreatedAt:   createdAt,
+				UpdatedAt:   updatedAt,
+				DeletedAt:   nil,
+				ArchiveType: "db",
+				ArchiveMd5:  "PeWLToy+/ygCFXQdXlgUbQ==",
 			},
 		},
 		{
@@ -135,3 +154,324 @@ func TestClient_GetProject(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_PushProject(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"test.dig":       "+test:\n  echo>: test\n",
+		".digdag/status": "digdag state",
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Method = %v, want %v", r.Method, http.MethodPut)
+		}
+		if r.URL.Path != "/api/projects" {
+			t.Errorf("URL Path = %v, want : %v", r.URL.Path, "/api/projects")
+		}
+		if got := r.URL.Query().Get("project"); got != "test" {
+			t.Errorf("project = %v, want %v", got, "test")
+		}
+		if got := r.URL.Query().Get("revision"); got != "rev1" {
+			t.Errorf("revision = %v, want %v", got, "rev1")
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/gzip" {
+			t.Errorf("Content-Type = %v, want %v", got, "application/gzip")
+		}
+		files := readArchive(t, r.Body)
+		if !reflect.DeepEqual(files, map[string]string{"test.dig": "+test:\n  echo>: test\n"}) {
+			t.Errorf("archive = %v", files)
+		}
+		fmt.Fprintln(w, `{"id":"1","name":"test","revision":"rev1"}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	got, err := c.PushProject("test", "rev1", dir)
+	if err != nil {
+		t.Fatalf("Client.PushProject() error = %v", err)
+	}
+	want := &Project{ID: "1", Name: "test", Revision: "rev1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.PushProject() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_PushProject_Revision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("revision"); len(got) != 36 {
+			t.Errorf("revision = %v, want UUID", got)
+		}
+		fmt.Fprintln(w, `{"id":"1","name":"test"}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	if _, err := c.PushProject("test", "", t.TempDir()); err != nil {
+		t.Errorf("Client.PushProject() error = %v", err)
+	}
+}
+
+func TestClient_DownloadProjectArchive(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"test.dig":          "+test:\n  echo>: test\n",
+		"queries/query.sql": "SELECT 1",
+	})
+	archive, err := createProjectArchive(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		projectID string
+		revision  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		status  int
+		wantErr bool
+	}{
+		// Test cases
+		{
+			args:   args{projectID: "1", revision: "rev1"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "test latest revision",
+			args:   args{projectID: "1"},
+			status: http.StatusOK,
+		},
+		{
+			name:    "test project not found",
+			args:    args{projectID: "999"},
+			status:  http.StatusNotFound,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/projects/%s/archive", tt.args.projectID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				if _, ok := r.URL.Query()["revision"]; ok != (tt.args.revision != "") {
+					t.Errorf("revision = %v, want %v", r.URL.Query().Get("revision"), tt.args.revision)
+				}
+				w.WriteHeader(tt.status)
+				w.Write(archive.Bytes())
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			rc, err := c.DownloadProjectArchive(tt.args.projectID, tt.args.revision)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.DownloadProjectArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer rc.Close()
+
+			dst := t.TempDir()
+			if err := ExtractProjectArchive(rc, dst); err != nil {
+				t.Fatalf("ExtractProjectArchive() error = %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(dst, "queries", "query.sql"))
+			if err != nil || string(got) != "SELECT 1" {
+				t.Errorf("queries/query.sql = %v, %v, want %v", string(got), err, "SELECT 1")
+			}
+		})
+	}
+}
+
+func TestClient_GetProjectByID(t *testing.T) {
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-13T03:11:12Z")
+	deletedAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-14T03:11:12Z")
+	type args struct {
+		projectID string
+	}
+	tests := []struct {
+		name         string
+		res          string
+		status       int
+		args         args
+		want         *Project
+		wantErr      bool
+		wantNotFound bool
+	}{
+		// Test cases
+		{
+			args: args{projectID: "1"},
+			res: `
+			{
+				"id": "1",
+				"name": "test",
+				"revision": "59b4f254-dc4f-429d-b0d6-18a676bb9e5f",
+				"createdAt": "2017-06-13T03:11:12Z",
+				"updatedAt": "2017-06-13T03:11:12Z",
+				"deletedAt": "2017-06-14T03:11:12Z",
+				"archiveType": "db",
+				"archiveMd5": "PeWLToy+/ygCFXQdXlgUbQ=="
+			}
+			`,
+			status: http.StatusOK,
+			want: &Project{
+				ID:          "1",
+				Name:        "test",
+				Revision:    "59b4f254-dc4f-429d-b0d6-18a676bb9e5f",
+				CreatedAt:   createdAt,
+				UpdatedAt:   createdAt,
+				DeletedAt:   &deletedAt,
+				ArchiveType: "db",
+				ArchiveMd5:  "PeWLToy+/ygCFXQdXlgUbQ==",
+			},
+		},
+		{
+			name:         "test project not found",
+			args:         args{projectID: "999"},
+			res:          `{"message":"Resource does not exist: project id=999","status":404}`,
+			status:       http.StatusNotFound,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/projects/%s", tt.args.projectID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.GetProjectByID(tt.args.projectID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.GetProjectByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", IsNotFound(err), tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetProjectByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_DeleteProject(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("Method = %v, want %v", r.Method, http.MethodDelete)
+		}
+		if r.URL.Path != "/api/projects/1" {
+			t.Errorf("URL Path = %v, want : %v", r.URL.Path, "/api/projects/1")
+		}
+		fmt.Fprintln(w, `{"id":"1","name":"test","deletedAt":"2017-06-14T03:11:12Z"}`)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+	got, err := c.DeleteProject("1")
+	if err != nil {
+		t.Fatalf("Client.DeleteProject() error = %v", err)
+	}
+	if got.DeletedAt == nil {
+		t.Errorf("Client.DeleteProject() DeletedAt = nil, want not nil")
+	}
+}
+
+func TestClient_GetProjectRevisions(t *testing.T) {
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-13T03:11:12Z")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/projects/1/revisions" {
+			t.Errorf("URL Path = %v, want : %v", r.URL.Path, "/api/projects/1/revisions")
+		}
+		fmt.Fprintln(w, `
+		{
+			"revisions": [
+				{
+					"revision": "59b4f254-dc4f-429d-b0d6-18a676bb9e5f",
+					"createdAt": "2017-06-13T03:11:12Z",
+					"archiveType": "db",
+					"archiveMd5": "PeWLToy+/ygCFXQdXlgUbQ==",
+					"userInfo": {}
+				}
+			]
+		}
+		`)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+	got, err := c.GetProjectRevisions("1")
+	if err != nil {
+		t.Fatalf("Client.GetProjectRevisions() error = %v", err)
+	}
+	want := []*Revision{
+		{
+			Revision:    "59b4f254-dc4f-429d-b0d6-18a676bb9e5f",
+			CreatedAt:   createdAt,
+			ArchiveType: "db",
+			ArchiveMd5:  "PeWLToy+/ygCFXQdXlgUbQ==",
+			UserInfo:    map[string]interface{}{},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.GetProjectRevisions() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_DiffProjectRevisions(t *testing.T) {
+	archives := map[string][]byte{}
+	for revision, files := range map[string]map[string]string{
+		"rev1": {"test.dig": "+test:\n  echo>: test\n", "query.sql": "SELECT 1\n"},
+		"rev2": {"test.dig": "+test:\n  echo>: changed\n"},
+	} {
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+		archive, err := createProjectArchive(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archives[revision] = archive.Bytes()
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/projects/1/archive" {
+			t.Errorf("URL Path = %v, want : %v", r.URL.Path, "/api/projects/1/archive")
+		}
+		archive, ok := archives[r.URL.Query().Get("revision")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+
+	got, err := c.DiffProjectRevisions("1", "rev1", "rev2")
+	if err != nil {
+		t.Fatalf("Client.DiffProjectRevisions() error = %v", err)
+	}
+	want := &ProjectDiff{
+		ProjectID:    "1",
+		FromRevision: "rev1",
+		ToRevision:   "rev2",
+		Files: []*FileDiff{
+			{Name: "query.sql", Status: DiffRemoved, UnifiedDiff: "--- a/query.sql\n+++ /dev/null\n@@ -1 +0,0 @@\n-SELECT 1\n"},
+			{Name: "test.dig", Status: DiffModified, UnifiedDiff: "--- a/test.dig\n+++ b/test.dig\n@@ -1,2 +1,2 @@\n +test:\n-  echo>: test\n+  echo>: changed\n"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.DiffProjectRevisions() = %v, want %v", got, want)
+	}
+
+	if _, err := c.DiffProjectRevisions("1", "rev1", "rev3"); !IsNotFound(err) {
+		t.Errorf("Client.DiffProjectRevisions() error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,119 @@
+package digdag
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy is the policy for retrying requests on transient failures
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first request
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled on each retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and the wait requested by Retry-After
+	MaxDelay time.Duration
+	// RetryableStatusCodes is the list of response statuses to retry
+	RetryableStatusCodes []int
+	// RetryCreateAttempt allows replaying `PUT /api/attempts`.
+	// digdag-server dedupes attempts by session time and retry attempt name, so it is safe to opt in.
+	RetryCreateAttempt bool
+}
+
+// NewRetryPolicy return new retry policy with default values
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// isRetryableRequest reports whether a request can be replayed
+func isRetryableRequest(method string, ro *RequestOpts) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return ro.Idempotent
+}
+
+// shouldRetry reports whether the result of a request is a transient failure
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if resp == nil {
+		// Transport error (e.g. connection refused while digdag-server restarts)
+		return err != nil
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before the (n+1)th retry
+func (p *RetryPolicy) backoff(n int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxDelay > 0 && wait > p.MaxDelay {
+				wait = p.MaxDelay
+			}
+			return wait
+		}
+	}
+
+	delay := p.BaseDelay << uint(n)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Full jitter
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// parseRetryAfter parses Retry-After header given in seconds or HTTP-date
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,221 @@
+package digdag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRetryPolicy() *RetryPolicy {
+	p := NewRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 10 * time.Millisecond
+	return p
+}
+
+func TestClient_NewRequest_Retry(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		failures       int
+		status         int
+		retryAfter     string
+		idempotent     bool
+		wantRequests   int32
+		wantErr        bool
+		wantStatusCode int
+	}{
+		// Test cases
+		{
+			name:           "test retry GET until success",
+			method:         http.MethodGet,
+			failures:       2,
+			status:         http.StatusServiceUnavailable,
+			wantRequests:   3,
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "test retry honors Retry-After",
+			method:         http.MethodGet,
+			failures:       1,
+			status:         http.StatusTooManyRequests,
+			retryAfter:     "0",
+			wantRequests:   2,
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "test give up after max retries",
+			method:         http.MethodGet,
+			failures:       10,
+			status:         http.StatusBadGateway,
+			wantRequests:   4,
+			wantErr:        true,
+			wantStatusCode: http.StatusBadGateway,
+		},
+		{
+			name:           "test not retry non retryable status",
+			method:         http.MethodGet,
+			failures:       1,
+			status:         http.StatusInternalServerError,
+			wantRequests:   1,
+			wantErr:        true,
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:           "test not retry PUT by default",
+			method:         http.MethodPut,
+			failures:       1,
+			status:         http.StatusServiceUnavailable,
+			wantRequests:   1,
+			wantErr:        true,
+			wantStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "test retry idempotent PUT",
+			method:         http.MethodPut,
+			failures:       1,
+			status:         http.StatusServiceUnavailable,
+			idempotent:     true,
+			wantRequests:   2,
+			wantStatusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				if int(n) <= tt.failures {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				fmt.Fprintln(w, `{}`)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			c.RetryPolicy = newTestRetryPolicy()
+
+			ro := &RequestOpts{Idempotent: tt.idempotent}
+			resp, err := c.NewRequest(tt.method, "/api/projects", ro)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.NewRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("Client.NewRequest() StatusCode = %v, want %v", resp.StatusCode, tt.wantStatusCode)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("requests = %v, want %v", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_CreateNewAttempt_Retry(t *testing.T) {
+	tests := []struct {
+		name               string
+		retryCreateAttempt bool
+		wantRequests       int32
+		wantErr            bool
+	}{
+		// Test cases
+		{
+			name:         "test not retry create attempt by default",
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name:               "test retry create attempt when opted in",
+			retryCreateAttempt: true,
+			wantRequests:       2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				http.ServeFile(w, r, "testdata/new_attempt.json")
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			c.RetryPolicy = newTestRetryPolicy()
+			c.RetryPolicy.RetryCreateAttempt = tt.retryCreateAttempt
+
+			_, _, err := c.CreateNewAttempt("2", time.Date(2017, 6, 24, 0, 0, 0, 0, time.UTC), []string{"key=value"}, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.CreateNewAttempt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("requests = %v, want %v", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_NewRequest_RetryTransportError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	c := newTestClient(ts.URL)
+	c.RetryPolicy = newTestRetryPolicy()
+	c.RetryPolicy.MaxRetries = 1
+
+	_, err := c.NewRequest(http.MethodGet, "/api/projects", nil)
+	if err == nil {
+		t.Error("Client.NewRequest() error should not be nil")
+	}
+}
+
+func TestClient_NewRequestContext_RetryCancelled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	c.RetryPolicy = newTestRetryPolicy()
+	c.RetryPolicy.BaseDelay = time.Hour
+	c.RetryPolicy.MaxDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.NewRequestContext(ctx, http.MethodGet, "/api/projects", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Client.NewRequestContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		// Test cases
+		{name: "test empty", value: ""},
+		{name: "test seconds", value: "120", want: 120 * time.Second, wantOk: true},
+		{name: "test past date", value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOk: true},
+		{name: "test invalid", value: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseRetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package digdag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+type schedulesWrapper struct {
+	Schedules []*Schedule `json:"schedules"`
+}
+
+// Schedule is struct for digdag schedule
+type Schedule struct {
+	ID       string     `json:"id"`
+	Project  ProjectRef `json:"project"`
+	Workflow struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"workflow"`
+	NextRunTime      time.Time  `json:"nextRunTime"`
+	NextScheduleTime time.Time  `json:"nextScheduleTime"`
+	DisabledAt       *time.Time `json:"disabledAt"`
+}
+
+// Disabled reports whether the schedule is disabled
+func (s *Schedule) Disabled() bool {
+	return s.DisabledAt != nil
+}
+
+// SkipSchedule is struct for skipping a schedule.
+// Set Count and FromTime to skip by count, or NextTime to skip to a time.
+type SkipSchedule struct {
+	Count       int        `json:"count,omitempty"`
+	FromTime    *time.Time `json:"fromTime,omitempty"`
+	NextTime    *time.Time `json:"nextTime,omitempty"`
+	NextRunTime *time.Time `json:"nextRunTime,omitempty"`
+	DryRun      bool       `json:"dryRun"`
+}
+
+// GetSchedules to get schedules
+func (c *Client) GetSchedules() ([]*Schedule, error) {
+	return c.GetSchedulesContext(context.Background())
+}
+
+// GetSchedulesContext to get schedules with the given context
+func (c *Client) GetSchedulesContext(ctx context.Context) ([]*Schedule, error) {
+	spath := "/api/schedules"
+
+	var sw *schedulesWrapper
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &sw); err != nil {
+		return nil, err
+	}
+
+	return sw.Schedules, nil
+}
+
+// GetProjectSchedules to get schedules of the project. If workflowName is empty, all schedules of the project are returned.
+func (c *Client) GetProjectSchedules(projectID, workflowName string) ([]*Schedule, error) {
+	return c.GetProjectSchedulesContext(context.Background(), projectID, workflowName)
+}
+
+// GetProjectSchedulesContext to get schedules of the project with the given context
+func (c *Client) GetProjectSchedulesContext(ctx context.Context, projectID, workflowName string) ([]*Schedule, error) {
+	spath := fmt.Sprintf("/api/projects/%s/schedules", projectID)
+
+	ro := &RequestOpts{
+		Params: map[string]string{},
+	}
+	if workflowName != "" {
+		ro.Params["workflow"] = workflowName
+	}
+
+	var sw *schedulesWrapper
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &sw); err != nil {
+		return nil, err
+	}
+
+	return sw.Schedules, nil
+}
+
+// GetWorkflowSchedule to get the schedule of the workflow
+func (c *Client) GetWorkflowSchedule(workflow *Workflow) (*Schedule, error) {
+	return c.GetWorkflowScheduleContext(context.Background(), workflow)
+}
+
+// GetWorkflowScheduleContext to get the schedule of the workflow with the given context
+func (c *Client) GetWorkflowScheduleContext(ctx context.Context, workflow *Workflow) (*Schedule, error) {
+	schedules, err := c.GetProjectSchedulesContext(ctx, workflow.Project.ID, workflow.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// if an empty array (= workflow has no schedule)
+	if len(schedules) == 0 {
+		return nil, &NotFoundError{Name: workflow.Name, Err: ErrScheduleNotFound}
+	}
+
+	return schedules[0], nil
+}
+
+// GetSchedule to get schedule by schedule ID
+func (c *Client) GetSchedule(scheduleID string) (*Schedule, error) {
+	return c.GetScheduleContext(context.Background(), scheduleID)
+}
+
+// GetScheduleContext to get schedule by schedule ID with the given context
+func (c *Client) GetScheduleContext(ctx context.Context, scheduleID string) (*Schedule, error) {
+	spath := fmt.Sprintf("/api/schedules/%s", scheduleID)
+
+	schedule, err := c.doSchedule(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, notFound(err, "id="+scheduleID, ErrScheduleNotFound)
+	}
+
+	return schedule, nil
+}
+
+// EnableSchedule to enable the schedule
+func (c *Client) EnableSchedule(scheduleID string) (*Schedule, error) {
+	return c.EnableScheduleContext(context.Background(), scheduleID)
+}
+
+// EnableScheduleContext to enable the schedule with the given context
+func (c *Client) EnableScheduleContext(ctx context.Context, scheduleID string) (*Schedule, error) {
+	spath := fmt.Sprintf("/api/schedules/%s/enable", scheduleID)
+	return c.doSchedule(ctx, http.MethodPost, spath, &RequestOpts{Idempotent: true})
+}
+
+// DisableSchedule to disable the schedule
+func (c *Client) DisableSchedule(scheduleID string) (*Schedule, error) {
+	return c.DisableScheduleContext(context.Background(), scheduleID)
+}
+
+// DisableScheduleContext to disable the schedule with the given context
+func (c *Client) DisableScheduleContext(ctx context.Context, scheduleID string) (*Schedule, error) {
+	spath := fmt.Sprintf("/api/schedules/%s/disable", scheduleID)
+	return c.doSchedule(ctx, http.MethodPost, spath, &RequestOpts{Idempotent: true})
+}
+
+// SkipSchedule to skip the schedule by count or to a time
+func (c *Client) SkipSchedule(scheduleID string, skip *SkipSchedule) (*Schedule, error) {
+	return c.SkipScheduleContext(context.Background(), scheduleID, skip)
+}
+
+// SkipScheduleContext to skip the schedule by count or to a time with the given context
+func (c *Client) SkipScheduleContext(ctx context.Context, scheduleID string, skip *SkipSchedule) (*Schedule, error) {
+	spath := fmt.Sprintf("/api/schedules/%s/skip", scheduleID)
+
+	if skip == nil || (skip.Count <= 0 && skip.NextTime == nil) {
+		return nil, fmt.Errorf("either count or next time is required to skip schedule `%s`", scheduleID)
+	}
+	// digdag-server requires nextTime or both of fromTime and count
+	if skip.NextTime == nil && skip.FromTime == nil {
+		return nil, fmt.Errorf("from time is required to skip schedule `%s` by count", scheduleID)
+	}
+
+	body, err := json.Marshal(skip)
+	if err != nil {
+		return nil, err
+	}
+
+	ro := &RequestOpts{
+		Body: bytes.NewBuffer(body),
+		// Skipping by count is not idempotent
+		Idempotent: skip.DryRun || skip.Count <= 0,
+	}
+
+	return c.doSchedule(ctx, http.MethodPost, spath, ro)
+}
+
+func (c *Client) doSchedule(ctx context.Context, method, spath string, ro *RequestOpts) (*Schedule, error) {
+	var schedule *Schedule
+	resp, err := c.NewRequestContext(ctx, method, spath, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// Backfill is struct for backfilling a schedule
+type Backfill struct {
+	FromTime time.Time `json:"fromTime"`
+	// AttemptName is the retry attempt name of the created attempts. If empty, a random UUID is used.
+	AttemptName string `json:"attemptName"`
+	Count       int    `json:"count,omitempty"`
+	DryRun      bool   `json:"dryRun"`
+}
+
+// BackfillResult is the response of backfill.
+// On dry run, Attempts are the would-be attempts with their session times and are not created.
+type BackfillResult struct {
+	ID       string     `json:"id"`
+	Project  ProjectRef `json:"project"`
+	Workflow struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"workflow"`
+	Attempts []*Attempt `json:"attempts"`
+}
+
+// BackfillSchedule to run attempts of the schedule from FromTime
+func (c *Client) BackfillSchedule(scheduleID string, backfill *Backfill) (*BackfillResult, error) {
+	return c.BackfillScheduleContext(context.Background(), scheduleID, backfill)
+}
+
+// BackfillScheduleContext to run attempts of the schedule from FromTime with the given context
+func (c *Client) BackfillScheduleContext(ctx context.Context, scheduleID string, backfill *Backfill) (*BackfillResult, error) {
+	spath := fmt.Sprintf("/api/schedules/%s/backfill", scheduleID)
+
+	if backfill == nil || backfill.FromTime.IsZero() {
+		return nil, fmt.Errorf("from time is required to backfill schedule `%s`", scheduleID)
+	}
+
+	b := *backfill
+	if b.AttemptName == "" {
+		b.AttemptName = uuid.NewV4().String()
+	}
+
+	body, err := json.Marshal(&b)
+	if err != nil {
+		return nil, err
+	}
+
+	ro := &RequestOpts{
+		Body:       bytes.NewBuffer(body),
+		Idempotent: b.DryRun,
+	}
+
+	var result *BackfillResult
+	resp, err := c.NewRequestContext(ctx, http.MethodPost, spath, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,393 @@
+package digdag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestSchedule() *Schedule {
+	nextRunTime, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-25T00:00:00Z")
+	nextScheduleTime, _ := time.Parse("2006-01-02T15:04:05-07:00", "2017-06-25T00:00:00+00:00")
+	schedule := &Schedule{
+		ID:               "1",
+		Project:          ProjectRef{ID: "1", Name: "test"},
+		NextRunTime:      nextRunTime,
+		NextScheduleTime: nextScheduleTime,
+	}
+	schedule.Workflow.ID = "2"
+	schedule.Workflow.Name = "test"
+	return schedule
+}
+
+func TestClient_GetSchedules(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wantURLPath := "/api/schedules"
+		if r.URL.Path != wantURLPath {
+			t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+		}
+		http.ServeFile(w, r, "testdata/schedules.json")
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+	got, err := c.GetSchedules()
+	if err != nil {
+		t.Fatalf("Client.GetSchedules() error = %v", err)
+	}
+	want := []*Schedule{newTestSchedule()}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.GetSchedules() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_GetProjectSchedules(t *testing.T) {
+	type args struct {
+		projectID    string
+		workflowName string
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		// Test cases
+		{
+			args: args{projectID: "1", workflowName: "test"},
+		},
+		{
+			name: "test all workflows",
+			args: args{projectID: "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/projects/%s/schedules", tt.args.projectID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				if _, ok := r.URL.Query()["workflow"]; ok != (tt.args.workflowName != "") {
+					t.Errorf("workflow = %v, want %v", r.URL.Query().Get("workflow"), tt.args.workflowName)
+				}
+				http.ServeFile(w, r, "testdata/schedules.json")
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.GetProjectSchedules(tt.args.projectID, tt.args.workflowName)
+			if err != nil {
+				t.Fatalf("Client.GetProjectSchedules() error = %v", err)
+			}
+			want := []*Schedule{newTestSchedule()}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Client.GetProjectSchedules() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestClient_GetWorkflowSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		res     string
+		want    *Schedule
+		wantErr error
+	}{
+		// Test cases
+		{
+			res:  readFile("testdata/schedules.json"),
+			want: newTestSchedule(),
+		},
+		{
+			name:    "test workflow has no schedule",
+			res:     `{"schedules":[]}`,
+			wantErr: ErrScheduleNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/projects/1/schedules" || r.URL.Query().Get("workflow") != "test" {
+					t.Errorf("URL = %v, want : %v", r.URL, "/api/projects/1/schedules?workflow=test")
+				}
+				fmt.Fprintln(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			workflow := &Workflow{ID: "2", Name: "test", Project: ProjectRef{ID: "1", Name: "test"}}
+			got, err := c.GetWorkflowSchedule(workflow)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.GetWorkflowSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetWorkflowSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_GetSchedule(t *testing.T) {
+	tests := []struct {
+		name         string
+		scheduleID   string
+		status       int
+		res          string
+		want         *Schedule
+		wantNotFound bool
+	}{
+		// Test cases
+		{
+			scheduleID: "1",
+			status:     http.StatusOK,
+			res: `
+			{
+				"id": "1",
+				"project": {"id": "1", "name": "test"},
+				"workflow": {"id": "2", "name": "test"},
+				"nextRunTime": "2017-06-25T00:00:00Z",
+				"nextScheduleTime": "2017-06-25T00:00:00+00:00",
+				"disabledAt": null
+			}
+			`,
+			want: newTestSchedule(),
+		},
+		{
+			name:         "test schedule not found",
+			scheduleID:   "999",
+			status:       http.StatusNotFound,
+			res:          `{"message":"Resource does not exist: schedule id=999","status":404}`,
+			wantNotFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/schedules/%s", tt.scheduleID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.GetSchedule(tt.scheduleID)
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("Client.GetSchedule() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_EnableDisableSchedule(t *testing.T) {
+	disabledAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-06-24T00:00:00Z")
+	tests := []struct {
+		name         string
+		call         func(c *Client) (*Schedule, error)
+		wantURLPath  string
+		disabledAt   string
+		wantDisabled bool
+	}{
+		// Test cases
+		{
+			name:        "test enable schedule",
+			call:        func(c *Client) (*Schedule, error) { return c.EnableSchedule("1") },
+			wantURLPath: "/api/schedules/1/enable",
+			disabledAt:  "null",
+		},
+		{
+			name:         "test disable schedule",
+			call:         func(c *Client) (*Schedule, error) { return c.DisableSchedule("1") },
+			wantURLPath:  "/api/schedules/1/disable",
+			disabledAt:   `"2017-06-24T00:00:00Z"`,
+			wantDisabled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("Method = %v, want %v", r.Method, http.MethodPost)
+				}
+				if r.URL.Path != tt.wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, tt.wantURLPath)
+				}
+				fmt.Fprintf(w, `{"id":"1","disabledAt":%s}`, tt.disabledAt)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := tt.call(c)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got.Disabled() != tt.wantDisabled {
+				t.Errorf("Schedule.Disabled() = %v, want %v", got.Disabled(), tt.wantDisabled)
+			}
+			if tt.wantDisabled && !got.DisabledAt.Equal(disabledAt) {
+				t.Errorf("Schedule.DisabledAt = %v, want %v", got.DisabledAt, disabledAt)
+			}
+		})
+	}
+}
+
+func TestClient_SkipSchedule(t *testing.T) {
+	fromTime := time.Date(2017, 6, 24, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		skip     *SkipSchedule
+		wantBody map[string]interface{}
+		wantErr  bool
+	}{
+		// Test cases
+		{
+			name: "test skip by count",
+			skip: &SkipSchedule{Count: 2, FromTime: &fromTime},
+			wantBody: map[string]interface{}{
+				"count":    float64(2),
+				"fromTime": "2017-06-24T00:00:00Z",
+				"dryRun":   false,
+			},
+		},
+		{
+			name: "test skip to time with dry run",
+			skip: &SkipSchedule{NextTime: &fromTime, DryRun: true},
+			wantBody: map[string]interface{}{
+				"nextTime": "2017-06-24T00:00:00Z",
+				"dryRun":   true,
+			},
+		},
+		{
+			name:    "test count without from time",
+			skip:    &SkipSchedule{Count: 2},
+			wantErr: true,
+		},
+		{
+			name:    "test neither count nor next time",
+			skip:    &SkipSchedule{DryRun: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/api/schedules/1/skip" {
+					t.Errorf("Request = %v %v, want : POST /api/schedules/1/skip", r.Method, r.URL.Path)
+				}
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Decode() error = %v", err)
+				}
+				if !reflect.DeepEqual(body, tt.wantBody) {
+					t.Errorf("body = %v, want %v", body, tt.wantBody)
+				}
+				fmt.Fprintln(w, `{"id":"1"}`)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			_, err := c.SkipSchedule("1", tt.skip)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.SkipSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_BackfillSchedule(t *testing.T) {
+	fromTime := time.Date(2017, 6, 20, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		backfill  *Backfill
+		wantBody  map[string]interface{}
+		wantTimes []string
+		wantErr   bool
+	}{
+		// Test cases
+		{
+			name:     "test backfill dry run",
+			backfill: &Backfill{FromTime: fromTime, AttemptName: "backfill1", Count: 2, DryRun: true},
+			wantBody: map[string]interface{}{
+				"fromTime":    "2017-06-20T00:00:00Z",
+				"attemptName": "backfill1",
+				"count":       float64(2),
+				"dryRun":      true,
+			},
+			wantTimes: []string{"2017-06-20T00:00:00Z", "2017-06-21T00:00:00Z"},
+		},
+		{
+			name:     "test from time is required",
+			backfill: &Backfill{Count: 2},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/api/schedules/1/backfill" {
+					t.Errorf("Request = %v %v, want : POST /api/schedules/1/backfill", r.Method, r.URL.Path)
+				}
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Decode() error = %v", err)
+				}
+				if !reflect.DeepEqual(body, tt.wantBody) {
+					t.Errorf("body = %v, want %v", body, tt.wantBody)
+				}
+				fmt.Fprintln(w, `
+				{
+					"id": "1",
+					"project": {"id": "1", "name": "test"},
+					"workflow": {"id": "2", "name": "test"},
+					"attempts": [
+						{"id": "", "sessionTime": "2017-06-20T00:00:00+00:00", "retryAttemptName": "backfill1"},
+						{"id": "", "sessionTime": "2017-06-21T00:00:00+00:00", "retryAttemptName": "backfill1"}
+					]
+				}
+				`)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.BackfillSchedule("1", tt.backfill)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.BackfillSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var gotTimes []string
+			for _, a := range got.Attempts {
+				gotTimes = append(gotTimes, a.SessionTime.Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(gotTimes, tt.wantTimes) {
+				t.Errorf("Client.BackfillSchedule() session times = %v, want %v", gotTimes, tt.wantTimes)
+			}
+		})
+	}
+}
+
+func TestClient_BackfillSchedule_AttemptName(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b Backfill
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+			t.Errorf("Decode() error = %v", err)
+		}
+		if len(b.AttemptName) != 36 {
+			t.Errorf("attemptName = %v, want UUID", b.AttemptName)
+		}
+		fmt.Fprintln(w, `{"id":"1","attempts":[]}`)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+	backfill := &Backfill{FromTime: time.Date(2017, 6, 20, 0, 0, 0, 0, time.UTC)}
+	if _, err := c.BackfillSchedule("1", backfill); err != nil {
+		t.Errorf("Client.BackfillSchedule() error = %v", err)
+	}
+	if backfill.AttemptName != "" {
+		t.Errorf("given backfill should not be modified but AttemptName = %v", backfill.AttemptName)
+	}
+}
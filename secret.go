@@ -0,0 +1,174 @@
+package digdag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretKeySegment is a segment of a dot-separated secret key accepted by digdag-server
+var secretKeySegment = regexp.MustCompile(`^[a-zA-Z]([a-zA-Z0-9_-]*[a-zA-Z0-9])?$`)
+
+// validateSecretKey rejects a key which is not a secret key, e.g. `..` or `a/b` changing the request path
+func validateSecretKey(key string) error {
+	for _, segment := range strings.Split(key, ".") {
+		if !secretKeySegment.MatchString(segment) {
+			return fmt.Errorf("secret key `%s` is invalid, expected dot-separated identifiers", key)
+		}
+	}
+	return nil
+}
+
+type secretsWrapper struct {
+	Secrets []*Secret `json:"secrets"`
+}
+
+// Secret is struct for digdag project secret. The value is never returned by digdag-server.
+type Secret struct {
+	Key string `json:"key"`
+}
+
+// secretValue is struct for the request body to set a secret
+type secretValue struct {
+	Value string `json:"value"`
+}
+
+// SecretsSyncResult is the result of SyncSecrets
+type SecretsSyncResult struct {
+	Set     []string
+	Deleted []string
+}
+
+// ListSecrets to get secret keys of the project
+func (c *Client) ListSecrets(projectID string) ([]*Secret, error) {
+	return c.ListSecretsContext(context.Background(), projectID)
+}
+
+// ListSecretsContext to get secret keys of the project with the given context
+func (c *Client) ListSecretsContext(ctx context.Context, projectID string) ([]*Secret, error) {
+	spath := fmt.Sprintf("/api/projects/%s/secrets", projectID)
+
+	var sw *secretsWrapper
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp, &sw); err != nil {
+		return nil, err
+	}
+
+	return sw.Secrets, nil
+}
+
+// SetSecret to set a secret of the project.
+// key must be dot-separated identifiers like `db.password`.
+func (c *Client) SetSecret(projectID, key, value string) error {
+	return c.SetSecretContext(context.Background(), projectID, key, value)
+}
+
+// SetSecretContext to set a secret of the project with the given context
+func (c *Client) SetSecretContext(ctx context.Context, projectID, key, value string) error {
+	if err := validateSecretKey(key); err != nil {
+		return err
+	}
+
+	spath := fmt.Sprintf("/api/projects/%s/secrets/%s", projectID, key)
+
+	body, err := json.Marshal(&secretValue{Value: value})
+	if err != nil {
+		return err
+	}
+
+	ro := &RequestOpts{
+		Body:          bytes.NewBuffer(body),
+		Idempotent:    true,
+		SensitiveBody: true,
+	}
+
+	resp, err := c.NewRequestContext(ctx, http.MethodPut, spath, ro)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// DeleteSecret to delete a secret of the project
+func (c *Client) DeleteSecret(projectID, key string) error {
+	return c.DeleteSecretContext(context.Background(), projectID, key)
+}
+
+// DeleteSecretContext to delete a secret of the project with the given context
+func (c *Client) DeleteSecretContext(ctx context.Context, projectID, key string) error {
+	if err := validateSecretKey(key); err != nil {
+		return err
+	}
+
+	spath := fmt.Sprintf("/api/projects/%s/secrets/%s", projectID, key)
+
+	resp, err := c.NewRequestContext(ctx, http.MethodDelete, spath, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// SyncSecrets to reconcile the secrets of the project with desired.
+// All desired secrets are set since digdag-server does not return secret values.
+// If prune is true, secrets not in desired are deleted.
+// Nothing is changed if a desired key is invalid.
+func (c *Client) SyncSecrets(projectID string, desired map[string]string, prune bool) (*SecretsSyncResult, error) {
+	return c.SyncSecretsContext(context.Background(), projectID, desired, prune)
+}
+
+// SyncSecretsContext to reconcile the secrets of the project with desired with the given context
+func (c *Client) SyncSecretsContext(ctx context.Context, projectID string, desired map[string]string, prune bool) (*SecretsSyncResult, error) {
+	result := &SecretsSyncResult{}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		if err := validateSecretKey(key); err != nil {
+			return result, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var current []*Secret
+	if prune {
+		var err error
+		current, err = c.ListSecretsContext(ctx, projectID)
+		if err != nil {
+			return result, err
+		}
+		sort.Slice(current, func(i, j int) bool { return current[i].Key < current[j].Key })
+	}
+
+	for _, key := range keys {
+		if err := c.SetSecretContext(ctx, projectID, key, desired[key]); err != nil {
+			return result, err
+		}
+		result.Set = append(result.Set, key)
+	}
+
+	for _, secret := range current {
+		if _, ok := desired[secret.Key]; ok {
+			continue
+		}
+		if err := c.DeleteSecretContext(ctx, projectID, secret.Key); err != nil {
+			return result, err
+		}
+		result.Deleted = append(result.Deleted, secret.Key)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package digdag
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestSecretsServer returns the server storing secrets of project 1 in memory
+func newTestSecretsServer(t *testing.T, secrets map[string]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/api/projects/1/secrets" && r.Method == http.MethodGet {
+			var keys []string
+			for k := range secrets {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			var ss []string
+			for _, k := range keys {
+				ss = append(ss, fmt.Sprintf(`{"key":%q}`, k))
+			}
+			fmt.Fprintf(w, `{"secrets":[%s]}`, strings.Join(ss, ","))
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, "/api/projects/1/secrets/")
+		if key == r.URL.Path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var v secretValue
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				t.Errorf("Decode() error = %v", err)
+			}
+			secrets[key] = v.Value
+		case http.MethodDelete:
+			delete(secrets, key)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+func TestClient_ListSecrets(t *testing.T) {
+	ts := newTestSecretsServer(t, map[string]string{"b": "2", "a": "1"})
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+
+	got, err := c.ListSecrets("1")
+	if err != nil {
+		t.Fatalf("Client.ListSecrets() error = %v", err)
+	}
+	want := []*Secret{{Key: "a"}, {Key: "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.ListSecrets() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_SetSecret(t *testing.T) {
+	secrets := map[string]string{}
+	ts := newTestSecretsServer(t, secrets)
+	defer ts.Close()
+
+	logger := &testLogger{}
+	c, _ := NewClient(ts.URL, WithLogger(logger), WithVerbose(true))
+	if err := c.SetSecret("1", "db.password", "s3cr3t"); err != nil {
+		t.Fatalf("Client.SetSecret() error = %v", err)
+	}
+	if secrets["db.password"] != "s3cr3t" {
+		t.Errorf("secrets = %v, want db.password set", secrets)
+	}
+	for _, e := range logger.entries {
+		if strings.Contains(fmt.Sprint(e.args), "s3cr3t") {
+			t.Errorf("secret value should not be logged but %v", e.args)
+		}
+	}
+
+	if err := c.DeleteSecret("1", "db.password"); err != nil {
+		t.Fatalf("Client.DeleteSecret() error = %v", err)
+	}
+	if _, ok := secrets["db.password"]; ok {
+		t.Errorf("secrets = %v, want db.password deleted", secrets)
+	}
+}
+
+func TestClient_SyncSecrets(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     map[string]string
+		desired     map[string]string
+		prune       bool
+		want        *SecretsSyncResult
+		wantSecrets map[string]string
+	}{
+		// Test cases
+		{
+			name:        "test sync without prune",
+			current:     map[string]string{"a": "old", "c": "3"},
+			desired:     map[string]string{"a": "1", "b": "2"},
+			want:        &SecretsSyncResult{Set: []string{"a", "b"}},
+			wantSecrets: map[string]string{"a": "1", "b": "2", "c": "3"},
+		},
+		{
+			name:        "test sync with prune",
+			current:     map[string]string{"a": "old", "c": "3", "d": "4"},
+			desired:     map[string]string{"a": "1", "b": "2"},
+			prune:       true,
+			want:        &SecretsSyncResult{Set: []string{"a", "b"}, Deleted: []string{"c", "d"}},
+			wantSecrets: map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:        "test prune all",
+			current:     map[string]string{"a": "1"},
+			desired:     map[string]string{},
+			prune:       true,
+			want:        &SecretsSyncResult{Deleted: []string{"a"}},
+			wantSecrets: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestSecretsServer(t, tt.current)
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+
+			got, err := c.SyncSecrets("1", tt.desired, tt.prune)
+			if err != nil {
+				t.Fatalf("Client.SyncSecrets() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.SyncSecrets() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.current, tt.wantSecrets) {
+				t.Errorf("secrets = %v, want %v", tt.current, tt.wantSecrets)
+			}
+		})
+	}
+}
+
+func TestClient_SyncSecrets_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+
+	if _, err := c.SyncSecrets("999", map[string]string{"a": "1"}, true); !IsNotFound(err) {
+		t.Errorf("Client.SyncSecrets() error = %v, want not found", err)
+	}
+}
+
+func TestClient_SecretInvalidKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request = %v %v", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+	c := newTestClient(ts.URL)
+
+	for _, key := range []string{"", ".", "..", "a/b", "../a", "a..b", ".a", "a.", "1a", "a-"} {
+		if err := c.SetSecret("1", key, "v"); err == nil {
+			t.Errorf("Client.SetSecret(%q) error = nil, want error", key)
+		}
+		if err := c.DeleteSecret("1", key); err == nil {
+			t.Errorf("Client.DeleteSecret(%q) error = nil, want error", key)
+		}
+	}
+	if _, err := c.SyncSecrets("1", map[string]string{"a": "1", "..": "2"}, true); err == nil {
+		t.Errorf("Client.SyncSecrets() error = nil, want error")
+	}
+}
+
+func Test_validateSecretKey(t *testing.T) {
+	for _, key := range []string{"a", "db.password", "aws.s3.access_key-id", "a1.B2"} {
+		if err := validateSecretKey(key); err != nil {
+			t.Errorf("validateSecretKey(%q) error = %v", key, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package digdag
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,8 +13,8 @@ type sessionsWrapper struct {
 
 // Session is the struct for digdag session
 type Session struct {
-	ID       string `json:"id"`
-	Project  `json:"project"`
+	ID       string     `json:"id"`
+	Project  ProjectRef `json:"project"`
 	Workflow struct {
 		Name string `json:"name"`
 		ID   string `json:"id"`
@@ -21,23 +22,28 @@ type Session struct {
 	SessionUUID string    `json:"sessionUuid"`
 	SessionTime time.Time `json:"sessionTime"`
 	LastAttempt struct {
-		ID               string            `json:"id"`
-		RetryAttemptName interface{}       `json:"retryAttemptName"`
-		Done             bool              `json:"done"`
-		Success          bool              `json:"success"`
-		CancelRequested  bool              `json:"cancelRequested"`
-		Params           map[string]string `json:"params"`
-		CreatedAt        time.Time         `json:"createdAt"`
-		FinishedAt       time.Time         `json:"finishedAt"`
+		ID               string      `json:"id"`
+		RetryAttemptName interface{} `json:"retryAttemptName"`
+		Done             bool        `json:"done"`
+		Success          bool        `json:"success"`
+		CancelRequested  bool        `json:"cancelRequested"`
+		Params           Params      `json:"params"`
+		CreatedAt        time.Time   `json:"createdAt"`
+		FinishedAt       *time.Time  `json:"finishedAt"`
 	} `json:"lastAttempt"`
 }
 
 // GetSessions to get sessions
 func (c *Client) GetSessions() ([]*Session, error) {
+	return c.GetSessionsContext(context.Background())
+}
+
+// GetSessionsContext to get sessions with the given context
+func (c *Client) GetSessionsContext(ctx context.Context) ([]*Session, error) {
 	spath := "/api/sessions"
 
 	var sw *sessionsWrapper
-	resp, err := c.NewRequest(http.MethodGet, spath, nil)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +55,35 @@ func (c *Client) GetSessions() ([]*Session, error) {
 	return sw.Sessions, nil
 }
 
+// GetSession to get session by session ID
+func (c *Client) GetSession(sessionID string) (*Session, error) {
+	return c.GetSessionContext(context.Background(), sessionID)
+}
+
+// GetSessionContext to get session by session ID with the given context
+func (c *Client) GetSessionContext(ctx context.Context, sessionID string) (*Session, error) {
+	spath := fmt.Sprintf("/api/sessions/%s", sessionID)
+
+	var session *Session
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, notFound(err, "id="+sessionID, ErrSessionNotFound)
+	}
+
+	if err := decodeBody(resp, &session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
 // GetProjectWorkflowSessions to get sessions by projectID and workflow
 func (c *Client) GetProjectWorkflowSessions(projectID, workflowName string) ([]*Session, error) {
+	return c.GetProjectWorkflowSessionsContext(context.Background(), projectID, workflowName)
+}
+
+// GetProjectWorkflowSessionsContext to get sessions by projectID and workflow with the given context
+func (c *Client) GetProjectWorkflowSessionsContext(ctx context.Context, projectID, workflowName string) ([]*Session, error) {
 	spath := fmt.Sprintf("/api/projects/%s/sessions", projectID)
 
 	var sw *sessionsWrapper
@@ -60,7 +93,7 @@ func (c *Client) GetProjectWorkflowSessions(projectID, workflowName string) ([]*
 		},
 	}
 
-	resp, err := c.NewRequest(http.MethodGet, spath, ro)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, ro)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +104,7 @@ func (c *Client) GetProjectWorkflowSessions(projectID, workflowName string) ([]*
 
 	// if any sessions not found
 	if len(sw.Sessions) == 0 {
-		return nil, fmt.Errorf("any sessions not found at `%s` workflow", workflowName)
+		return nil, &NotFoundError{Name: workflowName, Err: ErrNoSessions}
 	}
 
 	return sw.Sessions, nil
@@ -54,10 +54,7 @@ func TestClient_GetSessions(t *testing.T) {
 			want: []*Session{
 				{
 					ID: "2",
-					Project: struct {
-						ID   string `json:"id"`
-						Name string `json:"name"`
-					}{
+					Project: ProjectRef{
 						ID:   "1",
 						Name: "test",
 					},
@@ -71,23 +68,23 @@ func TestClient_GetSessions(t *testing.T) {
 					SessionUUID: "eaf514b8-b40b-4aea-81e4-9f46c0e2d3d5",
 					SessionTime: sessionTime,
 					LastAttempt: struct {
-						ID               string            `json:"id"`
-						RetryAttemptName interface{}       `json:"retryAttemptName"`
-						Done             bool              `json:"done"`
-						Success          bool              `json:"success"`
-						CancelRequested  bool              `json:"cancelRequested"`
-						Params           map[string]string `json:"params"`
-						CreatedAt        time.Time         `json:"createdAt"`
-						FinishedAt       time.Time         `json:"finishedAt"`
+						ID               string      `json:"id"`
+						RetryAttemptName interface{} `json:"retryAttemptName"`
+						Done             bool        `json:"done"`
+						Success          bool        `json:"success"`
+						CancelRequested  bool        `json:"cancelRequested"`
+						Params           Params      `json:"params"`
+						CreatedAt        time.Time   `json:"createdAt"`
+						FinishedAt       *time.Time  `json:"finishedAt"`
 					}{
 						ID:               "2",
 						RetryAttemptName: nil,
 						Done:             true,
 						Success:          false,
 						CancelRequested:  false,
-						Params:           map[string]string{},
+						Params:           map[string]interface{}{},
 						CreatedAt:        createdAt,
-						FinishedAt:       finishedAt,
+						FinishedAt:       &finishedAt,
 					},
 				},
 			},
@@ -170,10 +167,7 @@ func TestClient_GetProjectWorkflowSessions(t *testing.T) {
 			want: []*Session{
 				{
 					ID: "2",
-					Project: struct {
-						ID   string `json:"id"`
-						Name string `json:"name"`
-					}{
+					Project: ProjectRef{
 						ID:   "1",
 						Name: "test",
 					},
@@ -187,23 +181,23 @@ func TestClient_GetProjectWorkflowSessions(t *testing.T) {
 					SessionUUID: "eaf514b8-b40b-4aea-81e4-9f46c0e2d3d5",
 					SessionTime: sessionTime,
 					LastAttempt: struct {
-						ID               string            `json:"id"`
-						RetryAttemptName interface{}       `json:"retryAttemptName"`
-						Done             bool              `json:"done"`
-						Success          bool              `json:"success"`
-						CancelRequested  bool              `json:"cancelRequested"`
-						Params           map[string]string `json:"params"`
-						CreatedAt        time.Time         `json:"createdAt"`
-						FinishedAt       time.Time         `json:"finishedAt"`
+						ID               string      `json:"id"`
+						RetryAttemptName interface{} `json:"retryAttemptName"`
+						Done             bool        `json:"done"`
+						Success          bool        `json:"success"`
+						CancelRequested  bool        `json:"cancelRequested"`
+						Params           Params      `json:"params"`
+						CreatedAt        time.Time   `json:"createdAt"`
+						FinishedAt       *time.Time  `json:"finishedAt"`
 					}{
 						ID:               "2",
 						RetryAttemptName: nil,
 						Done:             true,
 						Success:          false,
 						CancelRequested:  false,
-						Params:           map[string]string{},
+						Params:           map[string]interface{}{},
 						CreatedAt:        createdAt,
-						FinishedAt:       finishedAt,
+						FinishedAt:       &finishedAt,
 					},
 				},
 			},
@@ -236,3 +230,98 @@ func TestClient_GetProjectWorkflowSessions(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetSession(t *testing.T) {
+	sessionTime, _ := time.Parse("2006-01-02T15:04:05-07:00", "2017-10-08T04:37:42+00:00")
+	createdAt, _ := time.Parse("2006-01-02T15:04:05Z", "2017-10-08T04:37:42Z")
+
+	session := &Session{
+		ID:          "2",
+		Project:     ProjectRef{ID: "1", Name: "test"},
+		SessionUUID: "eaf514b8-b40b-4aea-81e4-9f46c0e2d3d5",
+		SessionTime: sessionTime,
+	}
+	session.Workflow.Name = "test"
+	session.Workflow.ID = "2"
+	session.LastAttempt.ID = "2"
+	session.LastAttempt.Params = map[string]interface{}{}
+	session.LastAttempt.CreatedAt = createdAt
+
+	type args struct {
+		sessionID string
+	}
+	tests := []struct {
+		name         string
+		args         args
+		res          string
+		status       int
+		want         *Session
+		wantErr      bool
+		wantNotFound bool
+	}{
+		// Test cases
+		{
+			args: args{sessionID: "2"},
+			res: `
+			{
+				"id": "2",
+				"project": {
+					"id": "1",
+					"name": "test"
+				},
+				"workflow": {
+					"name": "test",
+					"id": "2"
+				},
+				"sessionUuid": "eaf514b8-b40b-4aea-81e4-9f46c0e2d3d5",
+				"sessionTime": "2017-10-08T04:37:42+00:00",
+				"lastAttempt": {
+					"id": "2",
+					"retryAttemptName": null,
+					"done": false,
+					"success": false,
+					"cancelRequested": false,
+					"params": {},
+					"createdAt": "2017-10-08T04:37:42Z",
+					"finishedAt": null
+				}
+			}
+			`,
+			status: http.StatusOK,
+			want:   session,
+		},
+		{
+			name:         "test session not found",
+			args:         args{sessionID: "999"},
+			res:          `{"message":"Resource does not exist: session id=999","status":404}`,
+			status:       http.StatusNotFound,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/sessions/%s", tt.args.sessionID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.GetSession(tt.args.sessionID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.GetSession() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", IsNotFound(err), tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetSession() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
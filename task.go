@@ -1,6 +1,7 @@
 package digdag
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -29,11 +30,16 @@ type Task struct {
 
 // GetTasks to get tasks list
 func (c *Client) GetTasks(attemptID string) ([]*Task, error) {
+	return c.GetTasksContext(context.Background(), attemptID)
+}
+
+// GetTasksContext to get tasks list with the given context
+func (c *Client) GetTasksContext(ctx context.Context, attemptID string) ([]*Task, error) {
 	spath := fmt.Sprintf("/api/attempts/%s/tasks", attemptID)
 
 	var tw *tasksWrapper
 
-	resp, err := c.NewRequest(http.MethodGet, spath, nil)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -47,13 +53,18 @@ func (c *Client) GetTasks(attemptID string) ([]*Task, error) {
 
 // GetTaskResult to return task result
 func (c *Client) GetTaskResult(attemptIDs []string, taskName string) (*Task, error) {
+	return c.GetTaskResultContext(context.Background(), attemptIDs, taskName)
+}
+
+// GetTaskResultContext to return task result with the given context
+func (c *Client) GetTaskResultContext(ctx context.Context, attemptIDs []string, taskName string) (*Task, error) {
 	// Check the taskName has prefix `+`
 	if !strings.HasPrefix(taskName, "+") {
 		return nil, fmt.Errorf("task `%s` is invalid task name", taskName)
 	}
 
 	for _, attemptID := range attemptIDs {
-		tasks, err := c.GetTasks(attemptID)
+		tasks, err := c.GetTasksContext(ctx, attemptID)
 		if err != nil {
 			return nil, err
 		}
@@ -65,10 +76,10 @@ func (c *Client) GetTaskResult(attemptIDs []string, taskName string) (*Task, err
 					return tasks[k], nil
 				}
 
-				return nil, fmt.Errorf("task `%s` state is %s", taskName, state)
+				return nil, &TaskFailedError{TaskName: taskName, State: state}
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("task `%s` result not found", taskName)
+	return nil, &NotFoundError{Name: taskName, Err: ErrTaskNotFound}
 }
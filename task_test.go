@@ -359,3 +359,23 @@ func TestClient_GetTaskResult(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetTasks_Numbers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"tasks": [{"id": "1", "config": {"_retry": 3}, "storeParams": {"n": 1.5}}]}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	got, err := c.GetTasks("64")
+	if err != nil {
+		t.Fatalf("Client.GetTasks() error = %v", err)
+	}
+	// Only attempt params keep numbers as json.Number
+	if v, ok := got[0].Config["_retry"].(float64); !ok || v != 3 {
+		t.Errorf("Task.Config[_retry] = %#v, want float64(3)", got[0].Config["_retry"])
+	}
+	if v, ok := got[0].StoreParams["n"].(float64); !ok || v != 1.5 {
+		t.Errorf("Task.StoreParams[n] = %#v, want float64(1.5)", got[0].StoreParams["n"])
+	}
+}
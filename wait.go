@@ -0,0 +1,136 @@
+package digdag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWaitInterval    = 1 * time.Second
+	defaultWaitMaxInterval = 30 * time.Second
+)
+
+// WaitOptions is the options for WaitAttempt
+type WaitOptions struct {
+	// Interval is the wait before the first poll. Defaults to 1s.
+	Interval time.Duration
+	// MaxInterval caps the interval growing by Multiplier. Defaults to 30s.
+	MaxInterval time.Duration
+	// Multiplier grows the interval after each poll. Values <= 1 keep the interval constant.
+	Multiplier float64
+	// Timeout limits the whole wait. Zero means no limit other than the context.
+	Timeout time.Duration
+}
+
+// next returns the interval after d
+func (o *WaitOptions) next(d time.Duration) time.Duration {
+	if o.Multiplier <= 1 {
+		return d
+	}
+	next := time.Duration(float64(d) * o.Multiplier)
+	if next > o.MaxInterval {
+		next = o.MaxInterval
+	}
+	return next
+}
+
+// Errors matched by AttemptFailedError
+var (
+	ErrAttemptFailed   = errors.New("attempt failed")
+	ErrAttemptCanceled = errors.New("attempt canceled")
+)
+
+// AttemptFailedError is returned when an attempt finished without success.
+// It matches ErrAttemptFailed or, if the attempt was killed, ErrAttemptCanceled with errors.Is.
+type AttemptFailedError struct {
+	Attempt     *Attempt
+	FailedTasks []string
+	// TasksErr is the error of getting the tasks when FailedTasks could not be filled
+	TasksErr error
+}
+
+func (e *AttemptFailedError) Error() string {
+	msg := fmt.Sprintf("attempt `%s` failed", e.Attempt.ID)
+	if e.Attempt.CancelRequested {
+		msg = fmt.Sprintf("attempt `%s` canceled", e.Attempt.ID)
+	}
+	if len(e.FailedTasks) > 0 {
+		msg += ": failed tasks " + strings.Join(e.FailedTasks, ", ")
+	}
+	if e.TasksErr != nil {
+		msg += fmt.Sprintf(" (failed to get tasks: %v)", e.TasksErr)
+	}
+	return msg
+}
+
+// Unwrap returns ErrAttemptCanceled if the attempt was killed, otherwise ErrAttemptFailed
+func (e *AttemptFailedError) Unwrap() error {
+	if e.Attempt.CancelRequested {
+		return ErrAttemptCanceled
+	}
+	return ErrAttemptFailed
+}
+
+// WaitAttempt to poll the attempt until it is done and return the final attempt.
+// If the attempt did not succeed, *AttemptFailedError is returned with the final attempt.
+// On timeout or cancellation, the last polled attempt is returned with the context error.
+func (c *Client) WaitAttempt(ctx context.Context, attemptID string, opts *WaitOptions) (*Attempt, error) {
+	o := WaitOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultWaitInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultWaitMaxInterval
+	}
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	var last *Attempt
+	interval := o.Interval
+	for {
+		attempt, err := c.GetAttemptContext(ctx, attemptID)
+		if err != nil {
+			return last, err
+		}
+		last = attempt
+
+		if attempt.Done {
+			if attempt.Success {
+				return attempt, nil
+			}
+			return attempt, c.attemptFailedError(ctx, attempt)
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return attempt, err
+		}
+		interval = o.next(interval)
+	}
+}
+
+// attemptFailedError builds *AttemptFailedError with the failed tasks of the attempt
+func (c *Client) attemptFailedError(ctx context.Context, attempt *Attempt) error {
+	e := &AttemptFailedError{Attempt: attempt}
+
+	tasks, err := c.GetTasksContext(ctx, attempt.ID)
+	if err != nil {
+		e.TasksErr = err
+		return e
+	}
+
+	for _, task := range tasks {
+		if task.State == "error" {
+			e.FailedTasks = append(e.FailedTasks, task.FullName)
+		}
+	}
+	return e
+}
@@ -0,0 +1,184 @@
+package digdag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WaitAttempt(t *testing.T) {
+	tasks := `
+	{
+		"tasks": [
+			{"id": "1", "fullName": "+test", "state": "group_error", "isGroup": true},
+			{"id": "2", "fullName": "+test+ok", "state": "success"},
+			{"id": "3", "fullName": "+test+ng", "state": "error"},
+			{"id": "4", "fullName": "+test+ng2", "state": "error"}
+		]
+	}
+	`
+	tests := []struct {
+		name            string
+		final           string
+		polls           int32
+		opts            *WaitOptions
+		wantErr         error
+		wantFailedTasks []string
+		wantPolls       int32
+	}{
+		// Test cases
+		{
+			name:      "test success",
+			final:     `{"id": "27", "done": true, "success": true}`,
+			polls:     3,
+			opts:      &WaitOptions{Interval: time.Millisecond, Multiplier: 2, MaxInterval: 4 * time.Millisecond},
+			wantPolls: 3,
+		},
+		{
+			name:            "test failure",
+			final:           `{"id": "27", "done": true, "success": false}`,
+			polls:           2,
+			opts:            &WaitOptions{Interval: time.Millisecond},
+			wantErr:         ErrAttemptFailed,
+			wantFailedTasks: []string{"+test+ng", "+test+ng2"},
+			wantPolls:       2,
+		},
+		{
+			name:            "test canceled",
+			final:           `{"id": "27", "done": true, "success": false, "cancelRequested": true}`,
+			polls:           1,
+			wantErr:         ErrAttemptCanceled,
+			wantFailedTasks: []string{"+test+ng", "+test+ng2"},
+			wantPolls:       1,
+		},
+		{
+			name:    "test timeout",
+			final:   `{"id": "27", "done": true, "success": true}`,
+			polls:   1000,
+			opts:    &WaitOptions{Interval: time.Millisecond, Timeout: 20 * time.Millisecond},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/attempts/27":
+					if atomic.AddInt32(&polls, 1) < tt.polls {
+						fmt.Fprintln(w, `{"id": "27", "done": false, "success": false}`)
+						return
+					}
+					fmt.Fprintln(w, tt.final)
+				case "/api/attempts/27/tasks":
+					fmt.Fprintln(w, tasks)
+				default:
+					t.Errorf("unexpected URL Path = %v", r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.WaitAttempt(context.Background(), "27", tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.WaitAttempt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got == nil || got.ID != "27" {
+				t.Errorf("Client.WaitAttempt() = %v, want attempt 27", got)
+			}
+			var afe *AttemptFailedError
+			if errors.As(err, &afe) && !reflect.DeepEqual(afe.FailedTasks, tt.wantFailedTasks) {
+				t.Errorf("AttemptFailedError.FailedTasks = %v, want %v", afe.FailedTasks, tt.wantFailedTasks)
+			}
+			if tt.wantPolls > 0 && atomic.LoadInt32(&polls) != tt.wantPolls {
+				t.Errorf("polls = %v, want %v", atomic.LoadInt32(&polls), tt.wantPolls)
+			}
+		})
+	}
+}
+
+func TestClient_WaitAttempt_ContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"id": "27", "done": false, "success": false}`)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	c := newTestClient(ts.URL)
+	_, err := c.WaitAttempt(ctx, "27", &WaitOptions{Interval: time.Millisecond})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Client.WaitAttempt() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWaitOptions_next(t *testing.T) {
+	tests := []struct {
+		name string
+		opts WaitOptions
+		d    time.Duration
+		want time.Duration
+	}{
+		// Test cases
+		{
+			name: "test constant interval",
+			opts: WaitOptions{MaxInterval: time.Minute},
+			d:    time.Second,
+			want: time.Second,
+		},
+		{
+			name: "test multiplier",
+			opts: WaitOptions{Multiplier: 1.5, MaxInterval: time.Minute},
+			d:    2 * time.Second,
+			want: 3 * time.Second,
+		},
+		{
+			name: "test max interval",
+			opts: WaitOptions{Multiplier: 2, MaxInterval: 3 * time.Second},
+			d:    2 * time.Second,
+			want: 3 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.next(tt.d); got != tt.want {
+				t.Errorf("WaitOptions.next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_WaitAttempt_TasksError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/attempts/27/tasks" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, `{"id": "27", "done": true, "success": false}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(ts.URL)
+	got, err := c.WaitAttempt(context.Background(), "27", nil)
+	if !errors.Is(err, ErrAttemptFailed) {
+		t.Fatalf("Client.WaitAttempt() error = %v, want %v", err, ErrAttemptFailed)
+	}
+	if got == nil || got.ID != "27" {
+		t.Errorf("Client.WaitAttempt() = %v, want attempt 27", got)
+	}
+	var afe *AttemptFailedError
+	if !errors.As(err, &afe) {
+		t.Fatalf("Client.WaitAttempt() error = %v, want *AttemptFailedError", err)
+	}
+	var apiErr *APIError
+	if !errors.As(afe.TasksErr, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("AttemptFailedError.TasksErr = %v, want *APIError with status 500", afe.TasksErr)
+	}
+}
@@ -0,0 +1,236 @@
+package digdag
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WatchEventType is the type of WatchEvent
+type WatchEventType string
+
+// Watch event types
+const (
+	// TaskStarted is sent when a task becomes running
+	TaskStarted WatchEventType = "task_started"
+	// TaskStateChanged is sent when a task changes to a state other than running or retry waiting
+	TaskStateChanged WatchEventType = "task_state_changed"
+	// TaskRetried is sent when a task failed and is waiting for retry
+	TaskRetried WatchEventType = "task_retried"
+	// AttemptFinished is sent when an attempt is done
+	AttemptFinished WatchEventType = "attempt_finished"
+	// WatchError is sent when polling failed. The channel is closed after it.
+	WatchError WatchEventType = "error"
+)
+
+// WatchEvent is the event sent by WatchAttempt and WatchWorkflow
+type WatchEvent struct {
+	Type    WatchEventType
+	Attempt *Attempt
+	// Task and PrevState are set for task events. PrevState is empty for a newly seen task.
+	Task      *Task
+	PrevState string
+	Err       error
+}
+
+// WatchOptions is the options for WatchAttempt and WatchWorkflow
+type WatchOptions struct {
+	// Interval is the polling interval. Defaults to 1s.
+	Interval time.Duration
+}
+
+func (o *WatchOptions) interval() time.Duration {
+	if o == nil || o.Interval <= 0 {
+		return defaultWaitInterval
+	}
+	return o.Interval
+}
+
+// taskEventType returns the event type of a task changed to state
+func taskEventType(state string) WatchEventType {
+	switch state {
+	case "running":
+		return TaskStarted
+	case "retry_waiting", "group_retry_waiting":
+		return TaskRetried
+	default:
+		return TaskStateChanged
+	}
+}
+
+// diffTasks returns the events for tasks whose state differs from states and updates states
+func diffTasks(attempt *Attempt, states map[string]string, tasks []*Task) []WatchEvent {
+	var events []WatchEvent
+	for _, task := range tasks {
+		prev, ok := states[task.ID]
+		if ok && prev == task.State {
+			continue
+		}
+		states[task.ID] = task.State
+		events = append(events, WatchEvent{
+			Type:      taskEventType(task.State),
+			Attempt:   attempt,
+			Task:      task,
+			PrevState: prev,
+		})
+	}
+	return events
+}
+
+// watcher sends events until its context is done
+type watcher struct {
+	ctx      context.Context
+	events   chan WatchEvent
+	interval time.Duration
+}
+
+func newWatcher(ctx context.Context, opts *WatchOptions) *watcher {
+	return &watcher{
+		ctx:      ctx,
+		events:   make(chan WatchEvent),
+		interval: opts.interval(),
+	}
+}
+
+// send reports false if the context is done
+func (w *watcher) send(events ...WatchEvent) bool {
+	for _, e := range events {
+		select {
+		case w.events <- e:
+		case <-w.ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// fail sends err unless it is caused by the context
+func (w *watcher) fail(err error) {
+	if w.ctx.Err() != nil && errors.Is(err, w.ctx.Err()) {
+		return
+	}
+	w.send(WatchEvent{Type: WatchError, Err: err})
+}
+
+// WatchAttempt to poll the attempt and its tasks in the background and send their changes.
+// The channel is closed after AttemptFinished, WatchError or when ctx is done.
+func (c *Client) WatchAttempt(ctx context.Context, attemptID string, opts *WatchOptions) <-chan WatchEvent {
+	w := newWatcher(ctx, opts)
+
+	go func() {
+		defer close(w.events)
+
+		states := map[string]string{}
+		for {
+			attempt, err := c.GetAttemptContext(ctx, attemptID)
+			if err != nil {
+				w.fail(err)
+				return
+			}
+
+			tasks, err := c.GetTasksContext(ctx, attemptID)
+			if err != nil {
+				w.fail(err)
+				return
+			}
+
+			if !w.send(diffTasks(attempt, states, tasks)...) {
+				return
+			}
+
+			if attempt.Done {
+				w.send(WatchEvent{Type: AttemptFinished, Attempt: attempt})
+				return
+			}
+
+			if err := sleepContext(ctx, w.interval); err != nil {
+				return
+			}
+		}
+	}()
+
+	return w.events
+}
+
+// WatchWorkflow to poll the attempts of the workflow in the background and send the changes of their tasks.
+// Attempts already done when watching starts are ignored.
+// Only the newest attempts listed by digdag-server are polled, so an attempt pushed out of the list
+// by newer ones before it is done sends no more events including AttemptFinished.
+// The channel is closed after WatchError or when ctx is done.
+func (c *Client) WatchWorkflow(ctx context.Context, projectName, workflowName string, opts *WatchOptions) <-chan WatchEvent {
+	w := newWatcher(ctx, opts)
+
+	go func() {
+		defer close(w.events)
+
+		params := new(Attempt)
+		params.Project.Name = projectName
+		params.Workflow.Name = workflowName
+
+		states := map[string]map[string]string{}
+		finished := map[string]bool{}
+		for n := 0; ; n++ {
+			attempts, err := c.GetAttemptsContext(ctx, params, true)
+			if err != nil && !errors.Is(err, ErrAttemptNotFound) {
+				w.fail(err)
+				return
+			}
+
+			listed := make(map[string]bool, len(attempts))
+			// digdag-server returns the newest attempt first
+			for i := len(attempts) - 1; i >= 0; i-- {
+				attempt := attempts[i]
+				listed[attempt.ID] = true
+				if finished[attempt.ID] {
+					continue
+				}
+				if n == 0 && attempt.Done {
+					finished[attempt.ID] = true
+					continue
+				}
+
+				tasks, err := c.GetTasksContext(ctx, attempt.ID)
+				if err != nil {
+					w.fail(err)
+					return
+				}
+
+				if states[attempt.ID] == nil {
+					states[attempt.ID] = map[string]string{}
+				}
+				if !w.send(diffTasks(attempt, states[attempt.ID], tasks)...) {
+					return
+				}
+
+				if attempt.Done {
+					finished[attempt.ID] = true
+					delete(states, attempt.ID)
+					if !w.send(WatchEvent{Type: AttemptFinished, Attempt: attempt}) {
+						return
+					}
+				}
+			}
+			forgetUnlisted(listed, finished, states)
+
+			if err := sleepContext(ctx, w.interval); err != nil {
+				return
+			}
+		}
+	}()
+
+	return w.events
+}
+
+// forgetUnlisted drops the attempts not listed anymore so that watching a workflow for long does not grow the maps
+func forgetUnlisted(listed, finished map[string]bool, states map[string]map[string]string) {
+	for id := range finished {
+		if !listed[id] {
+			delete(finished, id)
+		}
+	}
+	for id := range states {
+		if !listed[id] {
+			delete(states, id)
+		}
+	}
+}
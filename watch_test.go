@@ -0,0 +1,237 @@
+package digdag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventString formats e for comparison
+func eventString(e WatchEvent) string {
+	switch e.Type {
+	case AttemptFinished:
+		return fmt.Sprintf("%s %s", e.Type, e.Attempt.ID)
+	case WatchError:
+		return string(e.Type)
+	default:
+		return fmt.Sprintf("%s %s %s %s->%s", e.Type, e.Attempt.ID, e.Task.FullName, e.PrevState, e.Task.State)
+	}
+}
+
+// collectEvents reads events until the channel is closed
+func collectEvents(t *testing.T, events <-chan WatchEvent) []string {
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return got
+			}
+			got = append(got, eventString(e))
+		case <-timeout:
+			t.Fatalf("channel is not closed, got %v", got)
+		}
+	}
+}
+
+func TestClient_WatchAttempt(t *testing.T) {
+	attempts := []string{
+		`{"id": "27", "done": false}`,
+		`{"id": "27", "done": false}`,
+		`{"id": "27", "done": false}`,
+		`{"id": "27", "done": true, "success": true}`,
+	}
+	tasks := []string{
+		`{"tasks": [{"id": "1", "fullName": "+w", "state": "planned"}, {"id": "2", "fullName": "+w+a", "state": "running"}]}`,
+		`{"tasks": [{"id": "1", "fullName": "+w", "state": "planned"}, {"id": "2", "fullName": "+w+a", "state": "retry_waiting"}]}`,
+		`{"tasks": [{"id": "1", "fullName": "+w", "state": "planned"}, {"id": "2", "fullName": "+w+a", "state": "running"}]}`,
+		`{"tasks": [{"id": "1", "fullName": "+w", "state": "success"}, {"id": "2", "fullName": "+w+a", "state": "success"}]}`,
+	}
+	tests := []struct {
+		name   string
+		status int
+		want   []string
+	}{
+		// Test cases
+		{
+			status: http.StatusOK,
+			want: []string{
+				"task_state_changed 27 +w ->planned",
+				"task_started 27 +w+a ->running",
+				"task_retried 27 +w+a running->retry_waiting",
+				"task_started 27 +w+a retry_waiting->running",
+				"task_state_changed 27 +w planned->success",
+				"task_state_changed 27 +w+a running->success",
+				"attempt_finished 27",
+			},
+		},
+		{
+			name:   "test error",
+			status: http.StatusInternalServerError,
+			want:   []string{"error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+				switch r.URL.Path {
+				case "/api/attempts/27":
+					n := atomic.AddInt32(&polls, 1)
+					fmt.Fprintln(w, attempts[n-1])
+				case "/api/attempts/27/tasks":
+					fmt.Fprintln(w, tasks[atomic.LoadInt32(&polls)-1])
+				default:
+					t.Errorf("unexpected URL Path = %v", r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got := collectEvents(t, c.WatchAttempt(context.Background(), "27", &WatchOptions{Interval: time.Millisecond}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.WatchAttempt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_WatchAttempt_ContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/attempts/27":
+			fmt.Fprintln(w, `{"id": "27", "done": false}`)
+		case "/api/attempts/27/tasks":
+			fmt.Fprintln(w, `{"tasks": [{"id": "1", "fullName": "+w", "state": "running"}]}`)
+		}
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newTestClient(ts.URL)
+	events := c.WatchAttempt(ctx, "27", &WatchOptions{Interval: time.Millisecond})
+
+	// Stop reading while the watcher is sending
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	got := collectEvents(t, events)
+	if len(got) > 1 {
+		t.Errorf("Client.WatchAttempt() = %v, want at most one event", got)
+	}
+}
+
+func TestClient_WatchWorkflow(t *testing.T) {
+	attempts := []string{
+		`{"attempts": [{"id": "2", "done": false}, {"id": "1", "done": true}]}`,
+		`{"attempts": [{"id": "3", "done": false}, {"id": "2", "done": true}, {"id": "1", "done": true}]}`,
+	}
+	tasks := map[string][]string{
+		"1": {`{"tasks": []}`, `{"tasks": []}`},
+		"2": {
+			`{"tasks": [{"id": "1", "fullName": "+w", "state": "running"}]}`,
+			`{"tasks": [{"id": "1", "fullName": "+w", "state": "success"}]}`,
+		},
+		"3": {
+			`{"tasks": []}`,
+			`{"tasks": [{"id": "2", "fullName": "+w", "state": "running"}]}`,
+		},
+	}
+	want := []string{
+		"task_started 2 +w ->running",
+		"task_state_changed 2 +w running->success",
+		"attempt_finished 2",
+		"task_started 3 +w ->running",
+	}
+
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/attempts" {
+			if got := r.URL.Query().Get("project"); got != "test" {
+				t.Errorf("project = %v, want : %v", got, "test")
+			}
+			n := atomic.AddInt32(&polls, 1)
+			if n > int32(len(attempts)) {
+				n = int32(len(attempts))
+			}
+			fmt.Fprintln(w, attempts[n-1])
+			return
+		}
+		var id string
+		fmt.Sscanf(r.URL.Path, "/api/attempts/%1s/tasks", &id)
+		if id == "1" {
+			t.Errorf("tasks of the finished attempt are requested")
+		}
+		n := atomic.LoadInt32(&polls)
+		if n > int32(len(attempts)) {
+			n = int32(len(attempts))
+		}
+		fmt.Fprintln(w, tasks[id][n-1])
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newTestClient(ts.URL)
+	events := c.WatchWorkflow(ctx, "test", "test", &WatchOptions{Interval: time.Millisecond})
+
+	var got []string
+	for e := range events {
+		got = append(got, eventString(e))
+		if len(got) == len(want) {
+			cancel()
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.WatchWorkflow() = %v, want %v", got, want)
+	}
+}
+
+func Test_diffTasks(t *testing.T) {
+	attempt := &Attempt{ID: "27"}
+	states := map[string]string{"1": "running", "2": "planned"}
+	tasks := []*Task{
+		{ID: "1", FullName: "+w+a", State: "running"},
+		{ID: "2", FullName: "+w+b", State: "group_retry_waiting"},
+		{ID: "3", FullName: "+w+c", State: "blocked"},
+	}
+	want := []string{
+		"task_retried 27 +w+b planned->group_retry_waiting",
+		"task_state_changed 27 +w+c ->blocked",
+	}
+
+	var got []string
+	for _, e := range diffTasks(attempt, states, tasks) {
+		got = append(got, eventString(e))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffTasks() = %v, want %v", got, want)
+	}
+	wantStates := map[string]string{"1": "running", "2": "group_retry_waiting", "3": "blocked"}
+	if !reflect.DeepEqual(states, wantStates) {
+		t.Errorf("states = %v, want %v", states, wantStates)
+	}
+}
+
+func Test_forgetUnlisted(t *testing.T) {
+	listed := map[string]bool{"3": true, "4": true}
+	finished := map[string]bool{"1": true, "3": true}
+	states := map[string]map[string]string{"2": {"1": "running"}, "4": {"2": "running"}}
+
+	forgetUnlisted(listed, finished, states)
+	if want := map[string]bool{"3": true}; !reflect.DeepEqual(finished, want) {
+		t.Errorf("finished = %v, want %v", finished, want)
+	}
+	if want := map[string]map[string]string{"4": {"2": "running"}}; !reflect.DeepEqual(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+}
@@ -1,6 +1,7 @@
 package digdag
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -11,19 +12,24 @@ type workflowsWrapper struct {
 
 // Workflow is struct for digdag workflow
 type Workflow struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Project  `json:"project"`
-	Revision string `json:"revision"`
-	Timezone string `json:"timezone"`
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Project  ProjectRef `json:"project"`
+	Revision string     `json:"revision"`
+	Timezone string     `json:"timezone"`
 }
 
 // GetWorkflows to get projects
 func (c *Client) GetWorkflows() ([]*Workflow, error) {
+	return c.GetWorkflowsContext(context.Background())
+}
+
+// GetWorkflowsContext to get workflows with the given context
+func (c *Client) GetWorkflowsContext(ctx context.Context) ([]*Workflow, error) {
 	spath := "/api/workflows"
 
 	var ww *workflowsWrapper
-	resp, err := c.NewRequest(http.MethodGet, spath, nil)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +43,11 @@ func (c *Client) GetWorkflows() ([]*Workflow, error) {
 
 // GetWorkflow to get workflow by project ID and workflow name
 func (c *Client) GetWorkflow(projectID, workflowName string) (*Workflow, error) {
+	return c.GetWorkflowContext(context.Background(), projectID, workflowName)
+}
+
+// GetWorkflowContext to get workflow by project ID and workflow name with the given context
+func (c *Client) GetWorkflowContext(ctx context.Context, projectID, workflowName string) (*Workflow, error) {
 	spath := fmt.Sprintf("/api/projects/%s/workflows", projectID)
 
 	var ww *workflowsWrapper
@@ -46,7 +57,7 @@ func (c *Client) GetWorkflow(projectID, workflowName string) (*Workflow, error)
 		},
 	}
 
-	resp, err := c.NewRequest(http.MethodGet, spath, ro)
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, ro)
 	if err != nil {
 		return nil, err
 	}
@@ -57,8 +68,30 @@ func (c *Client) GetWorkflow(projectID, workflowName string) (*Workflow, error)
 
 	// if an empty array (= workflow not found)
 	if len(ww.Workflows) == 0 {
-		return nil, fmt.Errorf("workflow `%s` not found", workflowName)
+		return nil, &NotFoundError{Name: workflowName, Err: ErrWorkflowNotFound}
 	}
 
 	return ww.Workflows[0], nil
 }
+
+// GetWorkflowByID to get workflow by workflow ID
+func (c *Client) GetWorkflowByID(workflowID string) (*Workflow, error) {
+	return c.GetWorkflowByIDContext(context.Background(), workflowID)
+}
+
+// GetWorkflowByIDContext to get workflow by workflow ID with the given context
+func (c *Client) GetWorkflowByIDContext(ctx context.Context, workflowID string) (*Workflow, error) {
+	spath := fmt.Sprintf("/api/workflows/%s", workflowID)
+
+	var workflow *Workflow
+	resp, err := c.NewRequestContext(ctx, http.MethodGet, spath, nil)
+	if err != nil {
+		return nil, notFound(err, "id="+workflowID, ErrWorkflowNotFound)
+	}
+
+	if err := decodeBody(resp, &workflow); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
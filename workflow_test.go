@@ -42,7 +42,7 @@ func TestClient_TestGetWorkflows(t *testing.T) {
 				{
 					ID:   "9",
 					Name: "test",
-					Project: Project{
+					Project: ProjectRef{
 						ID:   "3",
 						Name: "test",
 					},
@@ -117,7 +117,7 @@ func TestClient_TestGetWorkflow(t *testing.T) {
 			want: &Workflow{
 				ID:   "9",
 				Name: "test",
-				Project: Project{
+				Project: ProjectRef{
 					ID:   "3",
 					Name: "test",
 				},
@@ -156,3 +156,84 @@ func TestClient_TestGetWorkflow(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetWorkflowByID(t *testing.T) {
+	type args struct {
+		workflowID string
+	}
+	tests := []struct {
+		name         string
+		args         args
+		res          string
+		status       int
+		want         *Workflow
+		wantErr      bool
+		wantNotFound bool
+	}{
+		// Test cases
+		{
+			args: args{workflowID: "9"},
+			res: `
+			{
+				"id": "9",
+				"name": "test",
+				"project": {
+					"id": "3",
+					"name": "test"
+				},
+				"revision": "2c9144e6-4d77-471b-baf6-f7d46f1b5296",
+				"timezone": "UTC",
+				"config": {
+					"+test": {
+						"echo>": "test"
+					}
+				}
+			}
+			`,
+			status: http.StatusOK,
+			want: &Workflow{
+				ID:   "9",
+				Name: "test",
+				Project: ProjectRef{
+					ID:   "3",
+					Name: "test",
+				},
+				Revision: "2c9144e6-4d77-471b-baf6-f7d46f1b5296",
+				Timezone: "UTC",
+			},
+		},
+		{
+			name:         "test workflow not found",
+			args:         args{workflowID: "999"},
+			res:          `{"message":"Resource does not exist: workflow id=999","status":404}`,
+			status:       http.StatusNotFound,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantURLPath := fmt.Sprintf("/api/workflows/%s", tt.args.workflowID)
+				if r.URL.Path != wantURLPath {
+					t.Errorf("URL Path = %v, want : %v", r.URL.Path, wantURLPath)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, tt.res)
+			}))
+			defer ts.Close()
+			c := newTestClient(ts.URL)
+			got, err := c.GetWorkflowByID(tt.args.workflowID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.GetWorkflowByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", IsNotFound(err), tt.wantNotFound)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.GetWorkflowByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}